@@ -0,0 +1,302 @@
+package whoishistory
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// ErrBudgetExceeded is matched by BudgetError with errors.Is.
+var ErrBudgetExceeded = errors.New("budget exceeded")
+
+// BudgetParams is used to create Budget. Zero limits mean no limit.
+type BudgetParams struct {
+	// MaxRecordsPerCall refuses purchases of domains with more records.
+	MaxRecordsPerCall int
+	// MaxRecords limits the total number of purchased records.
+	MaxRecords int
+	// MaxCredits limits the total number of credits spent on purchases.
+	MaxCredits int
+	// CreditsPerPurchase is the cost of one purchase. If it's not positive then 1 is used.
+	CreditsPerPurchase int
+}
+
+// LedgerEntry is the usage accounted by Budget.
+type LedgerEntry struct {
+	// Job is the name set with WithBudgetJob. It's empty for the total.
+	Job       string `json:"job"`
+	Previews  int    `json:"previews"`
+	Purchases int    `json:"purchases"`
+	Records   int    `json:"records"`
+	Credits   int    `json:"credits"`
+	// Refused is the number of purchases refused by the budget.
+	Refused int `json:"refused"`
+}
+
+// BudgetError is returned by Purchase when the budget refuses it.
+type BudgetError struct {
+	Domain string
+	// Records is the number of records reported by Preview.
+	Records int
+	// Limit is the exceeded limit.
+	Limit  int
+	Reason string
+}
+
+func (e *BudgetError) Error() string {
+	return "budget exceeded: " + e.Domain + " has " + strconv.Itoa(e.Records) + " records, " +
+		e.Reason + " limit is " + strconv.Itoa(e.Limit)
+}
+
+// Is reports whether the target is ErrBudgetExceeded.
+func (e *BudgetError) Is(target error) bool {
+	return target == ErrBudgetExceeded
+}
+
+// Budget guards purchases. Every Purchase first calls Preview with the same options
+// and is refused if the number of records exceeds the limits.
+// Credits are booked for every purchase answered with a successful status,
+// including ones which failed to decode, and records for the decoded ones.
+// Budget is safe for concurrent use and can be shared by several clients.
+type Budget struct {
+	mu sync.Mutex
+
+	params BudgetParams
+
+	total           LedgerEntry
+	jobs            map[string]*LedgerEntry
+	reservedRecords int
+	reservedCredits int
+}
+
+// NewBudget creates Budget with provided parameters.
+func NewBudget(params BudgetParams) *Budget {
+	if params.CreditsPerPurchase <= 0 {
+		params.CreditsPerPurchase = 1
+	}
+	return &Budget{
+		params: params,
+		jobs:   make(map[string]*LedgerEntry),
+	}
+}
+
+type budgetJobKey struct{}
+
+// WithBudgetJob returns the context which makes Budget account usage to the job.
+func WithBudgetJob(ctx context.Context, job string) context.Context {
+	return context.WithValue(ctx, budgetJobKey{}, job)
+}
+
+func budgetJob(ctx context.Context) string {
+	job, _ := ctx.Value(budgetJobKey{}).(string)
+	return job
+}
+
+// Total returns the usage of all jobs.
+func (b *Budget) Total() LedgerEntry {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.total
+}
+
+// Ledger returns the usage per job sorted by job name.
+// Usage outside of any job is reported with empty job name.
+func (b *Budget) Ledger() []LedgerEntry {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	ledger := make([]LedgerEntry, 0, len(b.jobs))
+	for _, e := range b.jobs {
+		ledger = append(ledger, *e)
+	}
+	sort.Slice(ledger, func(i, j int) bool {
+		return ledger[i].Job < ledger[j].Job
+	})
+	return ledger
+}
+
+// account applies fn to the total and to the job entry.
+// It must be called with the lock held.
+func (b *Budget) account(job string, fn func(e *LedgerEntry)) {
+	e, ok := b.jobs[job]
+	if !ok {
+		e = &LedgerEntry{Job: job}
+		b.jobs[job] = e
+	}
+	fn(e)
+	fn(&b.total)
+}
+
+func (b *Budget) preview(job string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.account(job, func(e *LedgerEntry) { e.Previews++ })
+}
+
+// reserve books records and credits for the purchase.
+func (b *Budget) reserve(job, name string, records int) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	p := b.params
+	var err *BudgetError
+	switch {
+	case p.MaxRecordsPerCall > 0 && records > p.MaxRecordsPerCall:
+		err = &BudgetError{Domain: name, Records: records, Limit: p.MaxRecordsPerCall, Reason: "per call records"}
+	case p.MaxRecords > 0 && b.total.Records+b.reservedRecords+records > p.MaxRecords:
+		err = &BudgetError{Domain: name, Records: records, Limit: p.MaxRecords, Reason: "total records"}
+	case p.MaxCredits > 0 && b.total.Credits+b.reservedCredits+p.CreditsPerPurchase > p.MaxCredits:
+		err = &BudgetError{Domain: name, Records: records, Limit: p.MaxCredits, Reason: "total credits"}
+	}
+	if err != nil {
+		b.account(job, func(e *LedgerEntry) { e.Refused++ })
+		return err
+	}
+
+	b.reservedRecords += records
+	b.reservedCredits += p.CreditsPerPurchase
+	return nil
+}
+
+// release cancels the reservation. If the purchase was charged then
+// the purchase, its credits and the received records are accounted.
+func (b *Budget) release(job string, reserved int, charged bool, records int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.reservedRecords -= reserved
+	b.reservedCredits -= b.params.CreditsPerPurchase
+
+	if !charged {
+		return
+	}
+	b.account(job, func(e *LedgerEntry) {
+		e.Purchases++
+		e.Records += records
+		e.Credits += b.params.CreditsPerPurchase
+	})
+}
+
+// charged reports whether the API charged for the purchase. The API charges
+// for every successful status even if the body then fails to decode or exceeds
+// MaxResponseSize, unless the body is an API error. Responses served from the cache are free.
+func charged(resp *Response, err error) bool {
+	var apiErr *ErrorMessage
+	if errors.As(err, &apiErr) {
+		return false
+	}
+	return resp != nil && resp.Response != nil && !resp.CacheHit &&
+		resp.StatusCode >= 200 && resp.StatusCode <= 299
+}
+
+// cacheChecker is implemented by services which can tell
+// whether the response is cached.
+type cacheChecker interface {
+	cached(purchase bool, name string, opts []Option) bool
+}
+
+// isCached reports whether the purchase is served from the cache.
+func (service *budgetService) isCached(name string, opts []Option) bool {
+	c, ok := service.HistoricService.(cacheChecker)
+	return ok && c.cached(true, name, opts)
+}
+
+// budgetService is HistoricService guarded by Budget
+type budgetService struct {
+	HistoricService
+	budget *Budget
+	// metrics receives purchases refused by the budget.
+	metrics Metrics
+}
+
+var _ HistoricService = &budgetService{}
+var _ HistoricStreamer = &budgetService{}
+
+// refuse records the purchase refused by the budget in metrics.
+func (service *budgetService) refuse(err error, start time.Time) {
+	if service.metrics == nil {
+		return
+	}
+	service.metrics.ObserveRequest(RequestMetrics{
+		Mode:        modePurchase,
+		StatusClass: statusClass(nil),
+		ErrorKind:   ErrorKind(err),
+		Duration:    time.Since(start),
+	})
+}
+
+// Purchase checks the number of records with Preview before purchasing them.
+// Cached purchases are free and served without Preview.
+func (service *budgetService) Purchase(ctx context.Context, name string, opts ...Option) ([]*WhoisRecord, *Response, error) {
+
+	if service.isCached(name, opts) {
+		records, resp, err := service.HistoricService.Purchase(ctx, name, append(opts[:len(opts):len(opts)], optionCacheOnly())...)
+		if !errors.Is(err, errCacheMiss) {
+			return records, resp, err
+		}
+	}
+
+	job := budgetJob(ctx)
+	start := time.Now()
+
+	count, resp, err := service.Preview(ctx, name, opts...)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	if err := service.budget.reserve(job, name, count); err != nil {
+		service.refuse(err, start)
+		return nil, resp, err
+	}
+
+	records, resp, err := service.HistoricService.Purchase(ctx, name, opts...)
+	service.budget.release(job, count, charged(resp, err), len(records))
+
+	return records, resp, err
+}
+
+// PurchaseEach checks the number of records with Preview before purchasing them.
+// Cached purchases are free and served without Preview.
+func (service *budgetService) PurchaseEach(ctx context.Context, name string, fn func(*WhoisRecord) error, opts ...Option) (int, *Response, error) {
+
+	if service.isCached(name, opts) {
+		n, resp, err := purchaseEach(ctx, service.HistoricService, name, fn, append(opts[:len(opts):len(opts)], optionCacheOnly())...)
+		if !errors.Is(err, errCacheMiss) {
+			return n, resp, err
+		}
+	}
+
+	job := budgetJob(ctx)
+	start := time.Now()
+
+	count, resp, err := service.Preview(ctx, name, opts...)
+	if err != nil {
+		return 0, resp, err
+	}
+
+	if err := service.budget.reserve(job, name, count); err != nil {
+		service.refuse(err, start)
+		return 0, resp, err
+	}
+
+	n, resp, err := purchaseEach(ctx, service.HistoricService, name, fn, opts...)
+	service.budget.release(job, count, charged(resp, err), n)
+
+	return n, resp, err
+}
+
+// Preview accounts the preview in the ledger.
+func (service *budgetService) Preview(ctx context.Context, name string, opts ...Option) (int, *Response, error) {
+
+	count, resp, err := service.HistoricService.Preview(ctx, name, opts...)
+	if err == nil {
+		service.budget.preview(budgetJob(ctx))
+	}
+
+	return count, resp, err
+}
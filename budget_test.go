@@ -0,0 +1,182 @@
+package whoishistory
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"sync/atomic"
+	"testing"
+)
+
+func TestBudget(t *testing.T) {
+	tests := []struct {
+		name      string
+		params    BudgetParams
+		domains   []string
+		wantErr   []string
+		wantTotal LedgerEntry
+	}{
+		{
+			name:      "no limits",
+			params:    BudgetParams{},
+			domains:   []string{"a.test", "bb.test"},
+			wantErr:   []string{"", ""},
+			wantTotal: LedgerEntry{Previews: 2, Purchases: 2, Records: 13, Credits: 2},
+		},
+		{
+			name:      "per call limit",
+			params:    BudgetParams{MaxRecordsPerCall: 6},
+			domains:   []string{"a.test", "bb.test", "c.test"},
+			wantErr:   []string{"", "budget exceeded: bb.test has 7 records, per call records limit is 6", ""},
+			wantTotal: LedgerEntry{Previews: 3, Purchases: 2, Records: 12, Credits: 2, Refused: 1},
+		},
+		{
+			name:      "total records limit",
+			params:    BudgetParams{MaxRecords: 13},
+			domains:   []string{"a.test", "bb.test", "c.test"},
+			wantErr:   []string{"", "", "budget exceeded: c.test has 6 records, total records limit is 13"},
+			wantTotal: LedgerEntry{Previews: 3, Purchases: 2, Records: 13, Credits: 2, Refused: 1},
+		},
+		{
+			name:      "total credits limit",
+			params:    BudgetParams{MaxCredits: 100, CreditsPerPurchase: 50},
+			domains:   []string{"a.test", "bb.test", "c.test"},
+			wantErr:   []string{"", "", "budget exceeded: c.test has 6 records, total credits limit is 100"},
+			wantTotal: LedgerEntry{Previews: 3, Purchases: 2, Records: 13, Credits: 100, Refused: 1},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stub := &stubHistoricService{}
+			budget := NewBudget(tt.params)
+			service := &budgetService{HistoricService: stub, budget: budget}
+
+			for i, d := range tt.domains {
+				records, _, err := service.Purchase(context.Background(), d)
+				checkErr(t, err, tt.wantErr[i])
+				if err != nil {
+					if !errors.Is(err, ErrBudgetExceeded) {
+						t.Errorf("errors.Is(ErrBudgetExceeded) failed for %v", err)
+					}
+					if records != nil {
+						t.Errorf("records = %v, want nil", records)
+					}
+				}
+			}
+
+			if got := budget.Total(); got != tt.wantTotal {
+				t.Errorf("Total() = %+v, want %+v", got, tt.wantTotal)
+			}
+			if n := int(atomic.LoadInt32(&stub.purchases)); n != tt.wantTotal.Purchases {
+				t.Errorf("purchases = %d, want %d", n, tt.wantTotal.Purchases)
+			}
+		})
+	}
+}
+
+func TestBudgetLedger(t *testing.T) {
+	stub := &stubHistoricService{failed: map[string]bool{"fail.test": true}}
+	budget := NewBudget(BudgetParams{MaxRecords: 100})
+	service := &budgetService{HistoricService: stub, budget: budget}
+
+	jobA := WithBudgetJob(context.Background(), "a")
+	jobB := WithBudgetJob(context.Background(), "b")
+
+	_, _, _ = service.Purchase(jobA, "a.test")
+	_, _, _ = service.Purchase(jobA, "aa.test")
+	_, _, _ = service.Purchase(jobB, "b.test")
+	_, _, _ = service.Preview(context.Background(), "c.test")
+	_, _, _ = service.Purchase(jobB, "fail.test")
+
+	want := []LedgerEntry{
+		{Job: "", Previews: 1},
+		{Job: "a", Previews: 2, Purchases: 2, Records: 13, Credits: 2},
+		{Job: "b", Previews: 1, Purchases: 1, Records: 6, Credits: 1},
+	}
+	if got := budget.Ledger(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Ledger() = %+v, want %+v", got, want)
+	}
+	if budget.reservedRecords != 0 || budget.reservedCredits != 0 {
+		t.Errorf("reservations left: %d records, %d credits", budget.reservedRecords, budget.reservedCredits)
+	}
+}
+
+func TestBudgetChargedPurchase(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.URL.Query().Get("mode") == modePreview {
+			_, _ = w.Write([]byte(`{"recordsCount":2}`))
+			return
+		}
+		// the purchase is charged but the body is cut off
+		_, _ = w.Write([]byte(`{"recordsCount":2,"records":[{"domainName":"a.test"},`))
+	}))
+	defer server.Close()
+
+	apiURL, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	metrics := &recordingMetrics{}
+	budget := NewBudget(BudgetParams{MaxCredits: 1})
+	api := NewClient(apiKey, ClientParams{
+		HTTPClient:      server.Client(),
+		HistoricBaseURL: apiURL,
+		Budget:          budget,
+		Metrics:         metrics,
+	})
+
+	_, _, err = api.Purchase(context.Background(), "a.test")
+	checkErr(t, err, "cannot parse response: unexpected end of JSON input")
+
+	if got, want := budget.Total(), (LedgerEntry{Previews: 1, Purchases: 1, Credits: 1}); got != want {
+		t.Errorf("Total() = %+v, want %+v", got, want)
+	}
+
+	_, _, err = api.Purchase(context.Background(), "a.test")
+	if !errors.Is(err, ErrBudgetExceeded) {
+		t.Fatalf("error = %v, want %v", err, ErrBudgetExceeded)
+	}
+
+	last := metrics.observed[len(metrics.observed)-1]
+	if last.Mode != modePurchase || last.StatusClass != "none" || last.ErrorKind != "budget" {
+		t.Errorf("refused purchase metrics = %+v", last)
+	}
+}
+
+func TestBudgetAPIErrorPurchase(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.URL.Query().Get("mode") == modePreview {
+			_, _ = w.Write([]byte(`{"recordsCount":2}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"code":403,"messages":"Access restricted. Check credits balance"}`))
+	}))
+	defer server.Close()
+
+	apiURL, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	budget := NewBudget(BudgetParams{MaxCredits: 2})
+	api := NewClient(apiKey, ClientParams{
+		HTTPClient:      server.Client(),
+		HistoricBaseURL: apiURL,
+		Budget:          budget,
+	})
+
+	for i := 0; i < 3; i++ {
+		_, _, err = api.Purchase(context.Background(), "a.test")
+		if !errors.Is(err, ErrInsufficientCredits) {
+			t.Fatalf("purchase %d: error = %v, want %v", i, err, ErrInsufficientCredits)
+		}
+	}
+
+	if got, want := budget.Total(), (LedgerEntry{Previews: 3}); got != want {
+		t.Errorf("Total() = %+v, want %+v", got, want)
+	}
+}
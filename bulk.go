@@ -0,0 +1,179 @@
+package whoishistory
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+)
+
+const defaultBulkWorkers = 4
+
+// BulkParams is used to create Bulk.
+type BulkParams struct {
+	// Workers is the number of domains processed concurrently.
+	// If it's not positive then 4 workers are used.
+	Workers int
+	// Purchase makes Bulk fetch records instead of counting them with Preview.
+	Purchase bool
+	// Options are applied to every request.
+	Options []Option
+	// OnProgress is called after every processed domain.
+	// It's called from worker goroutines and must be safe for concurrent use.
+	OnProgress func(BulkProgress)
+}
+
+// BulkResult is an outcome of one domain processed by Bulk.
+type BulkResult struct {
+	Domain string
+	// Records are the fetched records. It's nil in preview mode.
+	Records []*WhoisRecord
+	// Count is the number of records available in preview mode
+	// or the number of fetched records in purchase mode.
+	Count    int
+	Response *Response
+	Err      error
+}
+
+// BulkProgress is a snapshot of BulkRun counters.
+type BulkProgress struct {
+	// Queued is the number of domains taken from the input so far.
+	Queued int
+	// Done is the number of successfully processed domains.
+	Done int
+	// Failed is the number of domains processed with an error.
+	Failed int
+	// InFlight is the number of domains being processed right now.
+	InFlight int
+}
+
+// Bulk runs Preview or Purchase for lists of domains using a bounded worker pool.
+type Bulk struct {
+	service HistoricService
+	params  BulkParams
+}
+
+// NewBulk creates Bulk on top of the service.
+func NewBulk(service HistoricService, params BulkParams) *Bulk {
+	if params.Workers <= 0 {
+		params.Workers = defaultBulkWorkers
+	}
+	return &Bulk{service: service, params: params}
+}
+
+// BulkRun is a running bulk job.
+type BulkRun struct {
+	results chan BulkResult
+
+	queued   int64
+	done     int64
+	failed   int64
+	inFlight int64
+}
+
+// Results returns the channel of per-domain results. It's closed when
+// all domains are processed or the context is done and started domains are finished.
+// Every started domain is reported, including purchases completed after the context
+// is done, so the channel must be drained until it's closed, otherwise workers block.
+func (r *BulkRun) Results() <-chan BulkResult {
+	return r.results
+}
+
+// Progress returns current counters of the run.
+func (r *BulkRun) Progress() BulkProgress {
+	return BulkProgress{
+		Queued:   int(atomic.LoadInt64(&r.queued)),
+		Done:     int(atomic.LoadInt64(&r.done)),
+		Failed:   int(atomic.LoadInt64(&r.failed)),
+		InFlight: int(atomic.LoadInt64(&r.inFlight)),
+	}
+}
+
+// Run processes domains read from the channel until it's closed or the context is done.
+// Domains not started before the context is done are not reported.
+// Results of started domains are always delivered.
+func (b *Bulk) Run(ctx context.Context, domains <-chan string) *BulkRun {
+	run := &BulkRun{
+		results: make(chan BulkResult, b.params.Workers),
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(b.params.Workers)
+	for i := 0; i < b.params.Workers; i++ {
+		go func() {
+			defer wg.Done()
+			b.work(ctx, run, domains)
+		}()
+	}
+
+	go func() {
+		wg.Wait()
+		close(run.results)
+	}()
+
+	return run
+}
+
+// RunSlice processes the slice of domains.
+func (b *Bulk) RunSlice(ctx context.Context, domains []string) *BulkRun {
+	ch := make(chan string)
+	go func() {
+		defer close(ch)
+		for _, d := range domains {
+			select {
+			case ch <- d:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return b.Run(ctx, ch)
+}
+
+func (b *Bulk) work(ctx context.Context, run *BulkRun, domains <-chan string) {
+	for {
+		if ctx.Err() != nil {
+			return
+		}
+
+		var name string
+		var ok bool
+		select {
+		case <-ctx.Done():
+			return
+		case name, ok = <-domains:
+			if !ok {
+				return
+			}
+		}
+
+		atomic.AddInt64(&run.queued, 1)
+		atomic.AddInt64(&run.inFlight, 1)
+
+		res := b.process(ctx, name)
+
+		atomic.AddInt64(&run.inFlight, -1)
+		if res.Err != nil {
+			atomic.AddInt64(&run.failed, 1)
+		} else {
+			atomic.AddInt64(&run.done, 1)
+		}
+
+		if b.params.OnProgress != nil {
+			b.params.OnProgress(run.Progress())
+		}
+
+		// the result is counted and may be paid for, so it's delivered even after cancellation
+		run.results <- res
+	}
+}
+
+func (b *Bulk) process(ctx context.Context, name string) BulkResult {
+	res := BulkResult{Domain: name}
+	if b.params.Purchase {
+		res.Records, res.Response, res.Err = b.service.Purchase(ctx, name, b.params.Options...)
+		res.Count = len(res.Records)
+	} else {
+		res.Count, res.Response, res.Err = b.service.Preview(ctx, name, b.params.Options...)
+	}
+	return res
+}
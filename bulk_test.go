@@ -0,0 +1,201 @@
+package whoishistory
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// stubHistoricService returns as many records as the domain name length.
+type stubHistoricService struct {
+	delay time.Duration
+	// uncancelable makes requests complete regardless of the context.
+	uncancelable bool
+	failed       map[string]bool
+	active       int32
+	maxActive    int32
+	purchases    int32
+}
+
+var errStub = errors.New("stub error")
+
+func (s *stubHistoricService) enter(ctx context.Context, name string) error {
+	n := atomic.AddInt32(&s.active, 1)
+	defer atomic.AddInt32(&s.active, -1)
+	for {
+		m := atomic.LoadInt32(&s.maxActive)
+		if n <= m || atomic.CompareAndSwapInt32(&s.maxActive, m, n) {
+			break
+		}
+	}
+	if s.uncancelable {
+		time.Sleep(s.delay)
+	} else if err := sleep(ctx, s.delay); err != nil {
+		return err
+	}
+	if s.failed[name] {
+		return errStub
+	}
+	return nil
+}
+
+func (s *stubHistoricService) Purchase(ctx context.Context, name string, opts ...Option) ([]*WhoisRecord, *Response, error) {
+	atomic.AddInt32(&s.purchases, 1)
+	if err := s.enter(ctx, name); err != nil {
+		return nil, nil, err
+	}
+	records := make([]*WhoisRecord, len(name))
+	for i := range records {
+		records[i] = &WhoisRecord{DomainName: name}
+	}
+	return records, &Response{Response: &http.Response{StatusCode: http.StatusOK}}, nil
+}
+
+func (s *stubHistoricService) PurchaseEach(ctx context.Context, name string, fn func(*WhoisRecord) error, opts ...Option) (int, *Response, error) {
+	records, resp, err := s.Purchase(ctx, name, opts...)
+	for i, rec := range records {
+		if err := fn(rec); err != nil {
+			return i + 1, resp, err
+		}
+	}
+	return len(records), resp, err
+}
+
+func (s *stubHistoricService) Preview(ctx context.Context, name string, opts ...Option) (int, *Response, error) {
+	if err := s.enter(ctx, name); err != nil {
+		return 0, nil, err
+	}
+	return len(name), &Response{}, nil
+}
+
+func TestBulk(t *testing.T) {
+	var domains []string
+	for i := 0; i < 50; i++ {
+		domains = append(domains, "domain"+strconv.Itoa(i)+".test")
+	}
+
+	for _, purchase := range []bool{false, true} {
+		t.Run("purchase "+strconv.FormatBool(purchase), func(t *testing.T) {
+			service := &stubHistoricService{
+				delay:  time.Millisecond,
+				failed: map[string]bool{"domain7.test": true, "domain42.test": true},
+			}
+
+			var mu sync.Mutex
+			var progress []BulkProgress
+
+			bulk := NewBulk(service, BulkParams{
+				Workers:  5,
+				Purchase: purchase,
+				OnProgress: func(p BulkProgress) {
+					mu.Lock()
+					defer mu.Unlock()
+					progress = append(progress, p)
+				},
+			})
+
+			run := bulk.RunSlice(context.Background(), domains)
+
+			var got []string
+			for res := range run.Results() {
+				if service.failed[res.Domain] {
+					if !errors.Is(res.Err, errStub) {
+						t.Errorf("%s: error = %v, want %v", res.Domain, res.Err, errStub)
+					}
+					continue
+				}
+				if res.Err != nil || res.Count != len(res.Domain) || (len(res.Records) == res.Count) != purchase {
+					t.Errorf("%s: unexpected result %+v", res.Domain, res)
+				}
+				got = append(got, res.Domain)
+			}
+
+			if len(got) != 48 {
+				t.Errorf("got %d results, want 48", len(got))
+			}
+			sort.Strings(got)
+			if got[0] != "domain0.test" {
+				t.Errorf("first domain = %s", got[0])
+			}
+
+			want := BulkProgress{Queued: 50, Done: 48, Failed: 2}
+			if p := run.Progress(); p != want {
+				t.Errorf("Progress() = %+v, want %+v", p, want)
+			}
+			if len(progress) != 50 {
+				t.Errorf("OnProgress called %d times, want 50", len(progress))
+			}
+			if m := atomic.LoadInt32(&service.maxActive); m > 5 {
+				t.Errorf("max concurrency = %d, want <= 5", m)
+			}
+		})
+	}
+}
+
+func TestBulkCancel(t *testing.T) {
+	service := &stubHistoricService{delay: 20 * time.Millisecond}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	domains := make(chan string)
+	go func() {
+		for i := 0; ; i++ {
+			select {
+			case domains <- "domain" + strconv.Itoa(i) + ".test":
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	run := NewBulk(service, BulkParams{Workers: 2, Purchase: true}).Run(ctx, domains)
+
+	n := 0
+	for range run.Results() {
+		n++
+		if n == 4 {
+			cancel()
+		}
+	}
+
+	p := run.Progress()
+	if p.InFlight != 0 {
+		t.Errorf("in flight = %d after the run", p.InFlight)
+	}
+	if p.Queued > 8 || int(atomic.LoadInt32(&service.purchases)) != p.Queued {
+		t.Errorf("run did not stop: %+v", p)
+	}
+	if n != p.Queued {
+		t.Errorf("got %d results, want %d", n, p.Queued)
+	}
+}
+
+func TestBulkCancelDeliversCompleted(t *testing.T) {
+	service := &stubHistoricService{delay: 20 * time.Millisecond, uncancelable: true}
+
+	domains := []string{"a.test", "b.test", "c.test", "d.test"}
+	for i := 0; i < 20; i++ {
+		ctx, cancel := context.WithCancel(context.Background())
+		run := NewBulk(service, BulkParams{Workers: 4, Purchase: true}).RunSlice(ctx, domains)
+
+		time.Sleep(5 * time.Millisecond)
+		cancel()
+
+		paid := 0
+		for res := range run.Results() {
+			if res.Err == nil && len(res.Records) > 0 {
+				paid++
+			}
+		}
+		if p := run.Progress(); paid != p.Done {
+			t.Fatalf("got %d purchased results, want %d", paid, p.Done)
+		}
+	}
+}
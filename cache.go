@@ -0,0 +1,248 @@
+package whoishistory
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Cache stores raw bodies of successful API responses.
+// Implementations must be safe for concurrent use.
+type Cache interface {
+	// Get returns the cached value and true if it's present and not expired.
+	Get(key string) ([]byte, bool)
+	// Set stores the value.
+	Set(key string, value []byte)
+}
+
+// cacheParam is a query parameter used by cache options.
+// It's removed from the query before sending the request.
+const cacheParam = "whoishistory.cache"
+
+const (
+	cacheBypass  = "bypass"
+	cacheRefresh = "refresh"
+	// cacheOnly makes the request fail with errCacheMiss instead of calling the API.
+	cacheOnly = "only"
+)
+
+// errCacheMiss is returned by requests made with optionCacheOnly
+// if the response is not cached.
+var errCacheMiss = errors.New("response is not cached")
+
+// optionCacheOnly makes the request served only from the cache.
+func optionCacheOnly() Option {
+	return func(v url.Values) {
+		v.Set(cacheParam, cacheOnly)
+	}
+}
+
+// OptionCacheBypass makes the request ignore the cache completely.
+func OptionCacheBypass() Option {
+	return func(v url.Values) {
+		v.Set(cacheParam, cacheBypass)
+	}
+}
+
+// OptionCacheRefresh makes the request skip the cached value and store the fresh one.
+func OptionCacheRefresh() Option {
+	return func(v url.Values) {
+		v.Set(cacheParam, cacheRefresh)
+	}
+}
+
+// cacheKey builds the key from the normalized domain name, the mode
+// and the rest of query values except the API key.
+func cacheKey(name string, q url.Values) string {
+	v := url.Values{}
+	for k, vv := range q {
+		switch k {
+		case "apiKey", "domainName", "outputFormat", cacheParam:
+			continue
+		}
+		v[k] = vv
+	}
+	name = strings.ToLower(strings.TrimSuffix(strings.TrimSpace(name), "."))
+	return name + "?" + v.Encode()
+}
+
+// cachedResponse creates Response for the value served from the cache.
+func cachedResponse(req *http.Request) *Response {
+	return &Response{
+		Response: &http.Response{
+			Status:     "200 OK",
+			StatusCode: http.StatusOK,
+			Proto:      "HTTP/1.1",
+			ProtoMajor: 1,
+			ProtoMinor: 1,
+			Header:     http.Header{"Content-Type": []string{mediaType}},
+			Request:    req,
+		},
+		CacheHit: true,
+	}
+}
+
+// MemoryCache is an in-memory LRU cache with entries expiring after TTL.
+type MemoryCache struct {
+	mu sync.Mutex
+
+	capacity int
+	ttl      time.Duration
+	items    map[string]*list.Element
+	order    *list.List
+
+	now func() time.Time
+}
+
+type memoryCacheEntry struct {
+	key     string
+	value   []byte
+	expires time.Time
+}
+
+var _ Cache = &MemoryCache{}
+
+// NewMemoryCache creates MemoryCache holding up to capacity entries.
+// Zero ttl means that entries never expire.
+func NewMemoryCache(capacity int, ttl time.Duration) *MemoryCache {
+	if capacity < 1 {
+		capacity = 1
+	}
+	return &MemoryCache{
+		capacity: capacity,
+		ttl:      ttl,
+		items:    make(map[string]*list.Element),
+		order:    list.New(),
+		now:      time.Now,
+	}
+}
+
+// Get returns the value and marks it as recently used.
+func (c *MemoryCache) Get(key string) ([]byte, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, ok := c.items[key]
+	if !ok {
+		return nil, false
+	}
+
+	entry := el.Value.(*memoryCacheEntry)
+	if !entry.expires.IsZero() && !c.now().Before(entry.expires) {
+		c.order.Remove(el)
+		delete(c.items, key)
+		return nil, false
+	}
+
+	c.order.MoveToFront(el)
+	return entry.value, true
+}
+
+// Set stores the value evicting the least recently used entry if the cache is full.
+func (c *MemoryCache) Set(key string, value []byte) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var expires time.Time
+	if c.ttl > 0 {
+		expires = c.now().Add(c.ttl)
+	}
+
+	if el, ok := c.items[key]; ok {
+		entry := el.Value.(*memoryCacheEntry)
+		entry.value = value
+		entry.expires = expires
+		c.order.MoveToFront(el)
+		return
+	}
+
+	c.items[key] = c.order.PushFront(&memoryCacheEntry{key: key, value: value, expires: expires})
+
+	for c.order.Len() > c.capacity {
+		el := c.order.Back()
+		c.order.Remove(el)
+		delete(c.items, el.Value.(*memoryCacheEntry).key)
+	}
+}
+
+// Len returns the number of entries in the cache including expired ones.
+func (c *MemoryCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.order.Len()
+}
+
+// FileCache is a cache storing every entry in a separate file.
+// Entries expire TTL after the file was modified.
+type FileCache struct {
+	dir string
+	ttl time.Duration
+
+	now func() time.Time
+}
+
+var _ Cache = &FileCache{}
+
+// NewFileCache creates FileCache in the directory. The directory is created if missing.
+// Zero ttl means that entries never expire.
+func NewFileCache(dir string, ttl time.Duration) (*FileCache, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("cannot create cache directory: %w", err)
+	}
+	return &FileCache{dir: dir, ttl: ttl, now: time.Now}, nil
+}
+
+func (c *FileCache) path(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(c.dir, hex.EncodeToString(sum[:])+".json")
+}
+
+// Get reads the entry file. Expired files are removed.
+func (c *FileCache) Get(key string) ([]byte, bool) {
+	path := c.path(key)
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, false
+	}
+	if c.ttl > 0 && c.now().Sub(info.ModTime()) >= c.ttl {
+		_ = os.Remove(path)
+		return nil, false
+	}
+
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, false
+	}
+	return b, true
+}
+
+// Set writes the entry file. Write errors are ignored.
+func (c *FileCache) Set(key string, value []byte) {
+	f, err := ioutil.TempFile(c.dir, ".tmp-*")
+	if err != nil {
+		return
+	}
+
+	_, err = f.Write(value)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(f.Name(), c.path(key))
+	}
+	if err != nil {
+		_ = os.Remove(f.Name())
+	}
+}
@@ -0,0 +1,236 @@
+package whoishistory
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestMemoryCache(t *testing.T) {
+	now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	c := NewMemoryCache(2, time.Minute)
+	c.now = func() time.Time { return now }
+
+	c.Set("a", []byte("1"))
+	c.Set("b", []byte("2"))
+	if _, ok := c.Get("a"); !ok {
+		t.Fatal("a is missing")
+	}
+
+	// b is the least recently used entry.
+	c.Set("c", []byte("3"))
+	if _, ok := c.Get("b"); ok {
+		t.Error("b is not evicted")
+	}
+	if v, ok := c.Get("c"); !ok || string(v) != "3" {
+		t.Errorf("Get(c) = %s, %v", v, ok)
+	}
+
+	now = now.Add(time.Minute)
+	if _, ok := c.Get("a"); ok {
+		t.Error("a is not expired")
+	}
+	if c.Len() != 1 {
+		t.Errorf("Len() = %d, want 1", c.Len())
+	}
+}
+
+func TestFileCache(t *testing.T) {
+	dir, cleanup := tempDir(t)
+	defer cleanup()
+
+	c, err := NewFileCache(dir, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := c.Get("a"); ok {
+		t.Error("a is present in empty cache")
+	}
+
+	c.Set("a", []byte("1"))
+	if v, ok := c.Get("a"); !ok || string(v) != "1" {
+		t.Errorf("Get(a) = %s, %v", v, ok)
+	}
+
+	c.now = func() time.Time { return time.Now().Add(time.Minute) }
+	if _, ok := c.Get("a"); ok {
+		t.Error("a is not expired")
+	}
+}
+
+func TestCacheKey(t *testing.T) {
+	q := url.Values{
+		"apiKey":          []string{apiKey},
+		"domainName":      []string{"Test.Test."},
+		"outputFormat":    []string{"JSON"},
+		"mode":            []string{"purchase"},
+		"createdDateFrom": []string{"2020-01-01"},
+	}
+	if got, want := cacheKey(" Test.Test. ", q), "test.test?createdDateFrom=2020-01-01&mode=purchase"; got != want {
+		t.Errorf("cacheKey() = %v, want %v", got, want)
+	}
+}
+
+func TestAPI_Cache(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		if req.URL.Query().Get(cacheParam) != "" {
+			t.Errorf("cache parameter is sent: %v", req.URL)
+		}
+		if req.URL.Query().Get("domainName") == "fail.test" {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		_, _ = w.Write([]byte(`{"recordsCount":1,"records":[{"domainName":"test.test"}]}`))
+	}))
+	defer server.Close()
+
+	apiURL, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	api := NewClient(apiKey, ClientParams{
+		HTTPClient:      server.Client(),
+		HistoricBaseURL: apiURL,
+		Cache:           NewMemoryCache(10, time.Hour),
+	})
+
+	ctx := context.Background()
+	since := OptionSinceDate(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC))
+
+	tests := []struct {
+		name      string
+		domain    string
+		purchase  bool
+		opts      []Option
+		wantHit   bool
+		wantCalls int32
+	}{
+		{name: "miss", domain: "test.test", wantHit: false, wantCalls: 1},
+		{name: "hit", domain: "test.test", wantHit: true, wantCalls: 1},
+		{name: "normalized hit", domain: "TEST.test.", wantHit: true, wantCalls: 1},
+		{name: "other mode", domain: "test.test", purchase: true, wantHit: false, wantCalls: 2},
+		{name: "other mode hit", domain: "test.test", purchase: true, wantHit: true, wantCalls: 2},
+		{name: "other options", domain: "test.test", opts: []Option{since}, wantHit: false, wantCalls: 3},
+		{name: "bypass", domain: "test.test", opts: []Option{OptionCacheBypass()}, wantHit: false, wantCalls: 4},
+		{name: "refresh", domain: "test.test", opts: []Option{OptionCacheRefresh()}, wantHit: false, wantCalls: 5},
+		{name: "refreshed hit", domain: "test.test", wantHit: true, wantCalls: 5},
+		{name: "error", domain: "fail.test", wantHit: false, wantCalls: 6},
+		{name: "error is not cached", domain: "fail.test", wantHit: false, wantCalls: 7},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var resp *Response
+			if tt.purchase {
+				var records []*WhoisRecord
+				records, resp, err = api.Purchase(ctx, tt.domain, tt.opts...)
+				if err == nil && len(records) != 1 {
+					t.Errorf("records = %v", records)
+				}
+			} else {
+				var n int
+				n, resp, err = api.Preview(ctx, tt.domain, tt.opts...)
+				if err == nil && n != 1 {
+					t.Errorf("count = %v", n)
+				}
+			}
+			if tt.domain != "fail.test" && err != nil {
+				t.Fatal(err)
+			}
+			if resp.CacheHit != tt.wantHit {
+				t.Errorf("CacheHit = %v, want %v", resp.CacheHit, tt.wantHit)
+			}
+			if resp.StatusCode == 0 {
+				t.Error("status code is not set")
+			}
+			if got := atomic.LoadInt32(&calls); got != tt.wantCalls {
+				t.Errorf("calls = %v, want %v", got, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestAPI_CacheBudget(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		_, _ = w.Write([]byte(`{"recordsCount":1,"records":[{"domainName":"test.test"}]}`))
+	}))
+	defer server.Close()
+
+	apiURL, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	budget := NewBudget(BudgetParams{MaxCredits: 1})
+	api := NewClient(apiKey, ClientParams{
+		HTTPClient:      server.Client(),
+		HistoricBaseURL: apiURL,
+		Cache:           NewMemoryCache(10, time.Hour),
+		Budget:          budget,
+	})
+
+	ctx := context.Background()
+	for i := 0; i < 3; i++ {
+		records, resp, err := api.Purchase(ctx, "test.test")
+		if err != nil {
+			t.Fatalf("purchase %d: %v", i, err)
+		}
+		if len(records) != 1 || resp.CacheHit != (i > 0) {
+			t.Errorf("purchase %d: records = %v, CacheHit = %v", i, records, resp.CacheHit)
+		}
+	}
+
+	n, resp, err := api.PurchaseEach(ctx, "test.test", func(*WhoisRecord) error { return nil })
+	if err != nil || n != 1 || !resp.CacheHit {
+		t.Errorf("PurchaseEach() = %d, %+v, %v", n, resp, err)
+	}
+
+	// a preview and a purchase
+	if n := atomic.LoadInt32(&calls); n != 2 {
+		t.Errorf("calls = %d, want 2", n)
+	}
+	if got, want := budget.Total(), (LedgerEntry{Previews: 1, Purchases: 1, Records: 1, Credits: 1}); got != want {
+		t.Errorf("Total() = %+v, want %+v", got, want)
+	}
+}
+
+func TestAPI_CacheStreaming(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		_, _ = w.Write([]byte(`{"recordsCount":1,"records":[{"domainName":"test.test"}]}`))
+	}))
+	defer server.Close()
+
+	apiURL, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	api := NewClient(apiKey, ClientParams{
+		HTTPClient:      server.Client(),
+		HistoricBaseURL: apiURL,
+		Cache:           NewMemoryCache(10, time.Hour),
+	})
+
+	ctx := context.Background()
+	for i := 0; i < 2; i++ {
+		_, resp, err := api.PurchaseEach(ctx, "test.test", func(*WhoisRecord) error { return nil })
+		if err != nil || resp.CacheHit {
+			t.Errorf("PurchaseEach() = %+v, %v", resp, err)
+		}
+	}
+	if n := atomic.LoadInt32(&calls); n != 2 {
+		t.Errorf("calls = %d, want 2", n)
+	}
+}
@@ -4,9 +4,11 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"net/http"
 	"net/url"
 	"strconv"
+	"strings"
 )
 
 const (
@@ -25,6 +27,40 @@ type ClientParams struct {
 	WhoisBaseURL *url.URL
 	// Endpoint for `historic whois` service.
 	HistoricBaseURL *url.URL
+	// Retry is the policy applied to failed requests.
+	// If it's nil then requests are not retried.
+	Retry *RetryPolicy
+	// RateLimits are waited on by Client.Do before every attempt.
+	// If it's nil then requests are not limited.
+	RateLimits *RateLimits
+	// Budget guards purchases of the client with Preview calls.
+	// If it's nil then purchases are not checked.
+	Budget *Budget
+	// Cache serves repeated Preview and Purchase calls without requests.
+	// If it's nil then responses are not cached.
+	Cache Cache
+	// Middleware wraps every attempt made by Client.Do.
+	// The first middleware is the outermost one.
+	Middleware []Middleware
+	// OutcomeHooks are called in order after every HistoricService call.
+	OutcomeHooks []OutcomeHook
+	// Logger receives start and finish events of HistoricService calls.
+	// The API key is redacted from everything logged.
+	Logger Logger
+	// Metrics receives measurements of every HistoricService call.
+	Metrics Metrics
+	// Tracer creates spans around HistoricService calls.
+	Tracer Tracer
+	// MaxResponseSize limits the size of response bodies in bytes.
+	// Larger responses fail with ResponseSizeError. Zero means no limit.
+	MaxResponseSize int64
+	// TextLimits drop or truncate text fields of decoded records.
+	// If it's nil then text fields are kept as is.
+	TextLimits *TextLimits
+	// LenientTimes makes records with time values which cannot be parsed
+	// decode without them instead of failing the response.
+	// Skipped values are listed in WhoisRecord.InvalidTimes.
+	LenientTimes bool
 }
 
 // NewBasicClient creates Client with recommended parameters.
@@ -37,6 +73,14 @@ func NewClient(apiKey string, params ClientParams) *Client {
 
 	var err error
 
+	whoisBaseURL := params.WhoisBaseURL
+	if whoisBaseURL == nil {
+		whoisBaseURL, err = url.Parse(defaultWhoisURL)
+		if err != nil {
+			panic(err)
+		}
+	}
+
 	histBaseURL := params.HistoricBaseURL
 	if histBaseURL == nil {
 		histBaseURL, err = url.Parse(defaultHistoricWhoisURL)
@@ -54,9 +98,25 @@ func NewClient(apiKey string, params ClientParams) *Client {
 		client:    httpClient,
 		userAgent: userAgent,
 		apiKey:    apiKey,
+		retry:     params.Retry,
+		limits:    params.RateLimits,
+		cache:     params.Cache,
+		hooks:     params.OutcomeHooks,
+		logger:    params.Logger,
+		metrics:   params.Metrics,
+		tracer:    params.Tracer,
+
+		maxResponseSize: params.MaxResponseSize,
+		textLimits:      params.TextLimits,
+		lenientTimes:    params.LenientTimes,
 	}
+	client.doer = chain(httpClient.Do, params.Middleware)
 
+	client.WhoisService = &whoisServiceOp{client: client, baseURL: whoisBaseURL}
 	client.HistoricService = &historicServiceOp{client: client, baseURL: histBaseURL}
+	if params.Budget != nil {
+		client.HistoricService = &budgetService{HistoricService: client.HistoricService, budget: params.Budget, metrics: params.Metrics}
+	}
 
 	return client
 }
@@ -68,12 +128,36 @@ type Client struct {
 	userAgent string
 	apiKey    string
 
+	retry   *RetryPolicy
+	limits  *RateLimits
+	cache   Cache
+	hooks   []OutcomeHook
+	logger  Logger
+	metrics Metrics
+	tracer  Tracer
+	doer    Doer
+
+	maxResponseSize int64
+	textLimits      *TextLimits
+	lenientTimes    bool
+
+	WhoisService
 	HistoricService
 }
 
 // Response is a response wrapper.
 type Response struct {
 	*http.Response
+
+	// Attempts is the number of attempts made to get the response.
+	Attempts int
+	// CacheHit is true if the response is served from the cache.
+	CacheHit bool
+	// BytesRead is the size of the response body read from the network.
+	BytesRead int64
+	// Query holds parameters of the HistoricService call.
+	// It's nil for other requests.
+	Query *Query
 }
 
 // NewRequest creates a basic API request
@@ -94,14 +178,99 @@ func (c *Client) NewRequest(method string, u *url.URL, body io.Reader) (*http.Re
 	return req, nil
 }
 
-// Do sends an API request and returns the API response
-func (c *Client) Do(ctx context.Context, req *http.Request, v io.Writer) (response *Response, err error) {
+// Do sends an API request and returns the API response.
+// Transient failures are retried according to the client's RetryPolicy.
+func (c *Client) Do(ctx context.Context, req *http.Request, v io.Writer) (*Response, error) {
+	return c.do(ctx, req, func(_ *Response, body io.Reader) error {
+		if _, err := io.Copy(v, body); err != nil {
+			return fmt.Errorf("cannot read response: %w", err)
+		}
+		return nil
+	})
+}
+
+// bodyReader counts bytes read from the response body and keeps the read error.
+// If limit is positive then reading more than limit bytes fails with ResponseSizeError.
+type bodyReader struct {
+	r     io.Reader
+	n     int64
+	limit int64
+	err   error
+}
+
+func (b *bodyReader) Read(p []byte) (int, error) {
+	if b.err != nil {
+		return 0, b.err
+	}
+	if b.limit > 0 && int64(len(p)) > b.limit-b.n+1 {
+		p = p[:b.limit-b.n+1]
+	}
+
+	n, err := b.r.Read(p)
+	b.n += int64(n)
+	if b.limit > 0 && b.n > b.limit {
+		b.n = b.limit
+		b.err = &ResponseSizeError{Limit: b.limit, Size: -1}
+		return 0, b.err
+	}
+	if err != nil && err != io.EOF {
+		b.err = err
+	}
+	return n, err
+}
+
+// do sends the request and passes the response body to consume.
+// Errors of reading the body are reported as such whatever consume returns.
+func (c *Client) do(ctx context.Context, req *http.Request, consume func(resp *Response, body io.Reader) error) (response *Response, err error) {
 
 	req = req.WithContext(ctx)
 
-	resp, err := c.client.Do(req)
+	retry := c.retry.allows(req) && (req.Body == nil || req.GetBody != nil)
+	limiter := c.limits.limiter(req)
+
+	var resp *http.Response
+	attempt := 0
+	for {
+		attempt++
+
+		if limiter != nil {
+			if lerr := limiter.Wait(ctx); lerr != nil {
+				return nil, fmt.Errorf("cannot execute request: %w", lerr)
+			}
+		}
+
+		resp, err = c.doer(req)
+		if !retry || attempt >= c.retry.MaxAttempts {
+			break
+		}
+		if err != nil {
+			if !c.retry.retryableError(err) {
+				break
+			}
+		} else if !c.retry.retryableStatus(resp.StatusCode) {
+			break
+		}
+
+		wait := c.retry.delay(attempt, resp)
+		if resp != nil {
+			_, _ = io.Copy(ioutil.Discard, resp.Body)
+			_ = resp.Body.Close()
+		}
+
+		if serr := sleep(ctx, wait); serr != nil {
+			return nil, fmt.Errorf("cannot execute request: %w", serr)
+		}
+
+		if req.GetBody != nil {
+			body, berr := req.GetBody()
+			if berr != nil {
+				return nil, fmt.Errorf("cannot execute request: %w", berr)
+			}
+			req.Body = body
+		}
+	}
 	if err != nil {
-		return nil, fmt.Errorf("cannot execute request: %w", err)
+		return nil, fmt.Errorf("cannot execute request: %w", redactError(err))
 	}
 
 	defer func() {
@@ -110,36 +279,101 @@ func (c *Client) Do(ctx context.Context, req *http.Request, v io.Writer) (respon
 		}
 	}()
 
-	response = &Response{Response: resp}
+	response = &Response{Response: resp, Attempts: attempt}
 
-	_, err = io.Copy(v, resp.Body)
+	if limit := c.maxResponseSize; limit > 0 && resp.ContentLength > limit {
+		return response, &ResponseSizeError{Limit: limit, Size: resp.ContentLength}
+	}
+
+	body := &bodyReader{r: resp.Body, limit: c.maxResponseSize}
+	err = consume(response, body)
+	response.BytesRead = body.n
+	if sizeErr, ok := body.err.(*ResponseSizeError); ok {
+		return response, sizeErr
+	}
+	if body.err != nil {
+		return nil, fmt.Errorf("cannot read response: %w", body.err)
+	}
 	if err != nil {
-		return nil, fmt.Errorf("cannot read response: %w", err)
+		return response, err
 	}
 
-	return response, err
+	return response, nil
 }
 
 // ErrorResponse is returned when response's status code is not 2xx
 type ErrorResponse struct {
 	Response *http.Response
 	Message  string
+	// Body is the beginning of the response body.
+	Body string
+	// RequestID is the request identifier reported in response headers.
+	RequestID string
+	// APIError is the error message decoded from the response body if any.
+	APIError *ErrorMessage
 }
 
 func (e ErrorResponse) Error() string {
-	if e.Message != "" {
-		return "API failed with status code: " + strconv.Itoa(e.Response.StatusCode) + " (" + e.Message + ")"
+	msg := e.Message
+	if msg == "" && e.APIError != nil {
+		msg = e.APIError.Error()
+	}
+	if msg != "" {
+		return "API failed with status code: " + strconv.Itoa(e.Response.StatusCode) + " (" + msg + ")"
 	}
 	return "API failed with status code: " + strconv.Itoa(e.Response.StatusCode)
 }
 
-func checkResponse(r *http.Response) error {
+// Is reports whether the status code corresponds to the target sentinel error.
+func (e ErrorResponse) Is(target error) bool {
+	return target != nil && e.Response != nil && errorForCode(e.Response.StatusCode) == target
+}
+
+// Unwrap returns the decoded API error message.
+func (e ErrorResponse) Unwrap() error {
+	if e.APIError == nil {
+		return nil
+	}
+	return e.APIError
+}
+
+// maxErrorBodyLength limits the length of ErrorResponse.Body
+const maxErrorBodyLength = 512
+
+// requestIDHeaders are headers which may carry the request identifier
+var requestIDHeaders = []string{
+	"X-Request-Id",
+	"X-Amzn-Requestid",
+	"X-Amz-Cf-Id",
+	"Cf-Ray",
+}
+
+// checkResponse returns ErrorResponse if response's status code is not 2xx.
+// It returns nil otherwise.
+func checkResponse(r *http.Response, body string) *ErrorResponse {
 	if c := r.StatusCode; c >= 200 && c <= 299 {
 		return nil
 	}
 
-	var errorResponse = ErrorResponse{
+	return newErrorResponse(r, body)
+}
+
+// newErrorResponse creates ErrorResponse with the body excerpt and the request ID.
+func newErrorResponse(r *http.Response, body string) *ErrorResponse {
+	if len(body) > maxErrorBodyLength {
+		body = strings.ToValidUTF8(body[:maxErrorBodyLength], "") + "..."
+	}
+
+	var errorResponse = &ErrorResponse{
 		Response: r,
+		Body:     body,
+	}
+
+	for _, h := range requestIDHeaders {
+		if id := r.Header.Get(h); id != "" {
+			errorResponse.RequestID = id
+			break
+		}
 	}
 
 	return errorResponse
@@ -2,10 +2,12 @@ package whoishistory
 
 import (
 	"context"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
 	"strconv"
+	"strings"
 	"testing"
 )
 
@@ -114,7 +116,7 @@ func TestAPI_HistoricPurchase(t *testing.T) {
 				options: "whoisxmlapi.com",
 			},
 			want:    false,
-			wantErr: "API error: [123] test error",
+			wantErr: "API failed with status code: 500 (API error: [123] test error)",
 		},
 		{
 			name: "partial response 1",
@@ -186,3 +188,100 @@ func TestAPI_HistoricPurchase(t *testing.T) {
 		})
 	}
 }
+
+func TestAPI_ErrorResponse(t *testing.T) {
+
+	const (
+		bodyOK       = `{"recordsCount":1,"records":[{"domainName":"test.test"}]}`
+		bodyAPIError = `{"code":123,"messages":"test error"}`
+		bodyEmpty    = `{}`
+		bodyHTML     = `<html>Bad Gateway</html>`
+		bodyNone     = ``
+	)
+
+	tests := []struct {
+		name      string
+		status    int
+		body      string
+		want      int
+		wantErr   string
+		wantAPI   bool
+		wantIs    error
+		requestID string
+	}{
+		{name: "200 ok", status: 200, body: bodyOK, want: 1},
+		{name: "200 api error", status: 200, body: bodyAPIError, wantErr: "API error: [123] test error", wantAPI: true},
+		{name: "200 empty object", status: 200, body: bodyEmpty, want: 0},
+		{name: "200 non json", status: 200, body: bodyHTML, wantErr: "cannot parse response: invalid character '<' looking for beginning of value"},
+		{name: "200 no body", status: 200, body: bodyNone, wantErr: "cannot parse response: EOF"},
+		{name: "401 ok body", status: 401, body: bodyOK, wantErr: "API failed with status code: 401", wantIs: ErrUnauthorized},
+		{name: "401 api error", status: 401, body: bodyAPIError, wantErr: "API failed with status code: 401 (API error: [123] test error)", wantAPI: true, wantIs: ErrUnauthorized},
+		{name: "401 empty object", status: 401, body: bodyEmpty, wantErr: "API failed with status code: 401", wantIs: ErrUnauthorized},
+		{name: "401 non json", status: 401, body: bodyHTML, wantErr: "API failed with status code: 401", wantIs: ErrUnauthorized},
+		{name: "401 no body", status: 401, body: bodyNone, wantErr: "API failed with status code: 401", wantIs: ErrUnauthorized},
+		{name: "500 ok body", status: 500, body: bodyOK, wantErr: "API failed with status code: 500", wantIs: ErrServer},
+		{name: "500 api error", status: 500, body: bodyAPIError, wantErr: "API failed with status code: 500 (API error: [123] test error)", wantAPI: true, wantIs: ErrServer, requestID: "req-1"},
+		{name: "500 empty object", status: 500, body: bodyEmpty, wantErr: "API failed with status code: 500", wantIs: ErrServer},
+		{name: "502 non json", status: 502, body: bodyHTML, wantErr: "API failed with status code: 502", wantIs: ErrServer, requestID: "req-2"},
+		{name: "503 no body", status: 503, body: bodyNone, wantErr: "API failed with status code: 503", wantIs: ErrServer},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				if tt.requestID != "" {
+					w.Header().Set("X-Request-Id", tt.requestID)
+				}
+				w.WriteHeader(tt.status)
+				_, _ = w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			api := newAPI(server, "/")
+
+			got, _, err := api.Preview(context.Background(), "whoisxmlapi.com")
+			checkErr(t, err, tt.wantErr)
+			if got != tt.want {
+				t.Errorf("Preview() got = %v, want %v", got, tt.want)
+			}
+
+			var apiErr *ErrorMessage
+			if errors.As(err, &apiErr) != tt.wantAPI {
+				t.Errorf("errors.As(*ErrorMessage) = %v, want %v", !tt.wantAPI, tt.wantAPI)
+			}
+
+			if tt.status < 300 {
+				return
+			}
+
+			var respErr *ErrorResponse
+			if !errors.As(err, &respErr) {
+				t.Fatalf("errors.As(*ErrorResponse) failed for %v", err)
+			}
+			if respErr.Response.StatusCode != tt.status {
+				t.Errorf("status = %v, want %v", respErr.Response.StatusCode, tt.status)
+			}
+			if respErr.Body != tt.body {
+				t.Errorf("body = %q, want %q", respErr.Body, tt.body)
+			}
+			if respErr.RequestID != tt.requestID {
+				t.Errorf("request id = %q, want %q", respErr.RequestID, tt.requestID)
+			}
+			if !errors.Is(err, tt.wantIs) {
+				t.Errorf("errors.Is(%v) failed for %v", tt.wantIs, err)
+			}
+		})
+	}
+}
+
+func TestErrorResponseBodyExcerpt(t *testing.T) {
+	body := strings.Repeat("x", maxErrorBodyLength-1) + "ф" + strings.Repeat("y", 10)
+
+	e := checkResponse(&http.Response{StatusCode: 500, Header: http.Header{}}, body)
+	if want := strings.Repeat("x", maxErrorBodyLength-1) + "..."; e.Body != want {
+		t.Errorf("body = %q, want %q", e.Body, want)
+	}
+
+	if e := checkResponse(&http.Response{StatusCode: 204}, body); e != nil {
+		t.Errorf("checkResponse() = %v, want nil", e)
+	}
+}
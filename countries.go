@@ -0,0 +1,262 @@
+package whoishistory
+
+// country is an ISO 3166-1 country with alternative names used in whois records.
+type country struct {
+	alpha2  string
+	alpha3  string
+	name    string
+	aliases []string
+}
+
+// countries are ISO 3166-1 countries ordered by alpha-2 code.
+var countries = []country{
+	{"AD", "AND", "Andorra", []string{"Principality of Andorra"}},
+	{"AE", "ARE", "United Arab Emirates", []string{"UAE"}},
+	{"AF", "AFG", "Afghanistan", []string{"Islamic Republic of Afghanistan"}},
+	{"AG", "ATG", "Antigua and Barbuda", nil},
+	{"AI", "AIA", "Anguilla", nil},
+	{"AL", "ALB", "Albania", []string{"Republic of Albania"}},
+	{"AM", "ARM", "Armenia", []string{"Republic of Armenia"}},
+	{"AO", "AGO", "Angola", []string{"Republic of Angola"}},
+	{"AQ", "ATA", "Antarctica", nil},
+	{"AR", "ARG", "Argentina", []string{"Argentine Republic"}},
+	{"AS", "ASM", "American Samoa", nil},
+	{"AT", "AUT", "Austria", []string{"Republic of Austria", "Österreich", "Osterreich"}},
+	{"AU", "AUS", "Australia", nil},
+	{"AW", "ABW", "Aruba", nil},
+	{"AX", "ALA", "Åland Islands", []string{"Aland Islands"}},
+	{"AZ", "AZE", "Azerbaijan", []string{"Republic of Azerbaijan"}},
+	{"BA", "BIH", "Bosnia and Herzegovina", []string{"Republic of Bosnia and Herzegovina"}},
+	{"BB", "BRB", "Barbados", nil},
+	{"BD", "BGD", "Bangladesh", []string{"People's Republic of Bangladesh"}},
+	{"BE", "BEL", "Belgium", []string{"Kingdom of Belgium", "België", "Belgie", "Belgique"}},
+	{"BF", "BFA", "Burkina Faso", nil},
+	{"BG", "BGR", "Bulgaria", []string{"Republic of Bulgaria"}},
+	{"BH", "BHR", "Bahrain", []string{"Kingdom of Bahrain"}},
+	{"BI", "BDI", "Burundi", []string{"Republic of Burundi"}},
+	{"BJ", "BEN", "Benin", []string{"Republic of Benin"}},
+	{"BL", "BLM", "Saint Barthélemy", []string{"Saint Barthelemy"}},
+	{"BM", "BMU", "Bermuda", nil},
+	{"BN", "BRN", "Brunei Darussalam", []string{"Brunei"}},
+	{"BO", "BOL", "Bolivia, Plurinational State of", []string{"Plurinational State of Bolivia", "Bolivia"}},
+	{"BQ", "BES", "Bonaire, Sint Eustatius and Saba", nil},
+	{"BR", "BRA", "Brazil", []string{"Federative Republic of Brazil", "Brasil"}},
+	{"BS", "BHS", "Bahamas", []string{"Commonwealth of the Bahamas"}},
+	{"BT", "BTN", "Bhutan", []string{"Kingdom of Bhutan"}},
+	{"BV", "BVT", "Bouvet Island", nil},
+	{"BW", "BWA", "Botswana", []string{"Republic of Botswana"}},
+	{"BY", "BLR", "Belarus", []string{"Republic of Belarus"}},
+	{"BZ", "BLZ", "Belize", nil},
+	{"CA", "CAN", "Canada", nil},
+	{"CC", "CCK", "Cocos (Keeling) Islands", nil},
+	{"CD", "COD", "Congo, The Democratic Republic of the", []string{"Congo, Democratic Republic of the", "DR Congo", "DRC"}},
+	{"CF", "CAF", "Central African Republic", nil},
+	{"CG", "COG", "Congo", []string{"Republic of the Congo"}},
+	{"CH", "CHE", "Switzerland", []string{"Swiss Confederation", "Schweiz", "Suisse", "Svizzera"}},
+	{"CI", "CIV", "Côte d'Ivoire", []string{"Republic of Côte d'Ivoire", "Ivory Coast", "Cote d'Ivoire", "Republic of Cote d'Ivoire"}},
+	{"CK", "COK", "Cook Islands", nil},
+	{"CL", "CHL", "Chile", []string{"Republic of Chile"}},
+	{"CM", "CMR", "Cameroon", []string{"Republic of Cameroon"}},
+	{"CN", "CHN", "China", []string{"People's Republic of China", "PRC", "Zhongguo"}},
+	{"CO", "COL", "Colombia", []string{"Republic of Colombia"}},
+	{"CR", "CRI", "Costa Rica", []string{"Republic of Costa Rica"}},
+	{"CU", "CUB", "Cuba", []string{"Republic of Cuba"}},
+	{"CV", "CPV", "Cabo Verde", []string{"Republic of Cabo Verde", "Cape Verde"}},
+	{"CW", "CUW", "Curaçao", []string{"Curacao"}},
+	{"CX", "CXR", "Christmas Island", nil},
+	{"CY", "CYP", "Cyprus", []string{"Republic of Cyprus"}},
+	{"CZ", "CZE", "Czechia", []string{"Czech Republic", "Česko", "Cesko", "Česká republika", "Ceska republika"}},
+	{"DE", "DEU", "Germany", []string{"Federal Republic of Germany", "Deutschland"}},
+	{"DJ", "DJI", "Djibouti", []string{"Republic of Djibouti"}},
+	{"DK", "DNK", "Denmark", []string{"Kingdom of Denmark", "Danmark"}},
+	{"DM", "DMA", "Dominica", []string{"Commonwealth of Dominica"}},
+	{"DO", "DOM", "Dominican Republic", nil},
+	{"DZ", "DZA", "Algeria", []string{"People's Democratic Republic of Algeria", "Algérie", "Algerie"}},
+	{"EC", "ECU", "Ecuador", []string{"Republic of Ecuador"}},
+	{"EE", "EST", "Estonia", []string{"Republic of Estonia", "Eesti"}},
+	{"EG", "EGY", "Egypt", []string{"Arab Republic of Egypt"}},
+	{"EH", "ESH", "Western Sahara", nil},
+	{"ER", "ERI", "Eritrea", []string{"the State of Eritrea"}},
+	{"ES", "ESP", "Spain", []string{"Kingdom of Spain", "España", "Espana"}},
+	{"ET", "ETH", "Ethiopia", []string{"Federal Democratic Republic of Ethiopia"}},
+	{"FI", "FIN", "Finland", []string{"Republic of Finland", "Suomi"}},
+	{"FJ", "FJI", "Fiji", []string{"Republic of Fiji"}},
+	{"FK", "FLK", "Falkland Islands (Malvinas)", []string{"Falkland Islands"}},
+	{"FM", "FSM", "Micronesia, Federated States of", []string{"Federated States of Micronesia", "Micronesia"}},
+	{"FO", "FRO", "Faroe Islands", nil},
+	{"FR", "FRA", "France", []string{"French Republic"}},
+	{"GA", "GAB", "Gabon", []string{"Gabonese Republic"}},
+	{"GB", "GBR", "United Kingdom", []string{"United Kingdom of Great Britain and Northern Ireland", "UK", "Great Britain", "Britain", "England", "Scotland", "Wales", "Northern Ireland"}},
+	{"GD", "GRD", "Grenada", nil},
+	{"GE", "GEO", "Georgia", nil},
+	{"GF", "GUF", "French Guiana", nil},
+	{"GG", "GGY", "Guernsey", nil},
+	{"GH", "GHA", "Ghana", []string{"Republic of Ghana"}},
+	{"GI", "GIB", "Gibraltar", nil},
+	{"GL", "GRL", "Greenland", nil},
+	{"GM", "GMB", "Gambia", []string{"Republic of the Gambia"}},
+	{"GN", "GIN", "Guinea", []string{"Republic of Guinea"}},
+	{"GP", "GLP", "Guadeloupe", nil},
+	{"GQ", "GNQ", "Equatorial Guinea", []string{"Republic of Equatorial Guinea"}},
+	{"GR", "GRC", "Greece", []string{"Hellenic Republic", "Hellas", "Ellada"}},
+	{"GS", "SGS", "South Georgia and the South Sandwich Islands", nil},
+	{"GT", "GTM", "Guatemala", []string{"Republic of Guatemala"}},
+	{"GU", "GUM", "Guam", nil},
+	{"GW", "GNB", "Guinea-Bissau", []string{"Republic of Guinea-Bissau"}},
+	{"GY", "GUY", "Guyana", []string{"Republic of Guyana"}},
+	{"HK", "HKG", "Hong Kong", []string{"Hong Kong Special Administrative Region of China", "Hong Kong SAR"}},
+	{"HM", "HMD", "Heard Island and McDonald Islands", nil},
+	{"HN", "HND", "Honduras", []string{"Republic of Honduras"}},
+	{"HR", "HRV", "Croatia", []string{"Republic of Croatia", "Hrvatska"}},
+	{"HT", "HTI", "Haiti", []string{"Republic of Haiti"}},
+	{"HU", "HUN", "Hungary", []string{"Magyarország", "Magyarorszag"}},
+	{"ID", "IDN", "Indonesia", []string{"Republic of Indonesia"}},
+	{"IE", "IRL", "Ireland", []string{"Éire", "Eire"}},
+	{"IL", "ISR", "Israel", []string{"State of Israel"}},
+	{"IM", "IMN", "Isle of Man", nil},
+	{"IN", "IND", "India", []string{"Republic of India"}},
+	{"IO", "IOT", "British Indian Ocean Territory", nil},
+	{"IQ", "IRQ", "Iraq", []string{"Republic of Iraq"}},
+	{"IR", "IRN", "Iran, Islamic Republic of", []string{"Islamic Republic of Iran", "Iran"}},
+	{"IS", "ISL", "Iceland", []string{"Republic of Iceland", "Ísland"}},
+	{"IT", "ITA", "Italy", []string{"Italian Republic", "Italia"}},
+	{"JE", "JEY", "Jersey", nil},
+	{"JM", "JAM", "Jamaica", nil},
+	{"JO", "JOR", "Jordan", []string{"Hashemite Kingdom of Jordan"}},
+	{"JP", "JPN", "Japan", []string{"Nippon", "Nihon"}},
+	{"KE", "KEN", "Kenya", []string{"Republic of Kenya"}},
+	{"KG", "KGZ", "Kyrgyzstan", []string{"Kyrgyz Republic"}},
+	{"KH", "KHM", "Cambodia", []string{"Kingdom of Cambodia"}},
+	{"KI", "KIR", "Kiribati", []string{"Republic of Kiribati"}},
+	{"KM", "COM", "Comoros", []string{"Union of the Comoros"}},
+	{"KN", "KNA", "Saint Kitts and Nevis", []string{"Saint Kitts"}},
+	{"KP", "PRK", "Korea, Democratic People's Republic of", []string{"Democratic People's Republic of Korea", "North Korea"}},
+	{"KR", "KOR", "Korea, Republic of", []string{"South Korea", "Korea", "Republic of Korea", "Hanguk"}},
+	{"KW", "KWT", "Kuwait", []string{"State of Kuwait"}},
+	{"KY", "CYM", "Cayman Islands", nil},
+	{"KZ", "KAZ", "Kazakhstan", []string{"Republic of Kazakhstan"}},
+	{"LA", "LAO", "Lao People's Democratic Republic", []string{"Laos"}},
+	{"LB", "LBN", "Lebanon", []string{"Lebanese Republic"}},
+	{"LC", "LCA", "Saint Lucia", nil},
+	{"LI", "LIE", "Liechtenstein", []string{"Principality of Liechtenstein"}},
+	{"LK", "LKA", "Sri Lanka", []string{"Democratic Socialist Republic of Sri Lanka"}},
+	{"LR", "LBR", "Liberia", []string{"Republic of Liberia"}},
+	{"LS", "LSO", "Lesotho", []string{"Kingdom of Lesotho"}},
+	{"LT", "LTU", "Lithuania", []string{"Republic of Lithuania", "Lietuva"}},
+	{"LU", "LUX", "Luxembourg", []string{"Grand Duchy of Luxembourg"}},
+	{"LV", "LVA", "Latvia", []string{"Republic of Latvia", "Latvija"}},
+	{"LY", "LBY", "Libya", nil},
+	{"MA", "MAR", "Morocco", []string{"Kingdom of Morocco", "Maroc"}},
+	{"MC", "MCO", "Monaco", []string{"Principality of Monaco"}},
+	{"MD", "MDA", "Moldova, Republic of", []string{"Republic of Moldova", "Moldova"}},
+	{"ME", "MNE", "Montenegro", nil},
+	{"MF", "MAF", "Saint Martin (French part)", []string{"Saint Martin"}},
+	{"MG", "MDG", "Madagascar", []string{"Republic of Madagascar"}},
+	{"MH", "MHL", "Marshall Islands", []string{"Republic of the Marshall Islands"}},
+	{"MK", "MKD", "North Macedonia", []string{"Republic of North Macedonia", "Macedonia"}},
+	{"ML", "MLI", "Mali", []string{"Republic of Mali"}},
+	{"MM", "MMR", "Myanmar", []string{"Republic of Myanmar", "Burma"}},
+	{"MN", "MNG", "Mongolia", nil},
+	{"MO", "MAC", "Macao", []string{"Macao Special Administrative Region of China", "Macau"}},
+	{"MP", "MNP", "Northern Mariana Islands", []string{"Commonwealth of the Northern Mariana Islands"}},
+	{"MQ", "MTQ", "Martinique", nil},
+	{"MR", "MRT", "Mauritania", []string{"Islamic Republic of Mauritania"}},
+	{"MS", "MSR", "Montserrat", nil},
+	{"MT", "MLT", "Malta", []string{"Republic of Malta"}},
+	{"MU", "MUS", "Mauritius", []string{"Republic of Mauritius"}},
+	{"MV", "MDV", "Maldives", []string{"Republic of Maldives"}},
+	{"MW", "MWI", "Malawi", []string{"Republic of Malawi"}},
+	{"MX", "MEX", "Mexico", []string{"United Mexican States"}},
+	{"MY", "MYS", "Malaysia", nil},
+	{"MZ", "MOZ", "Mozambique", []string{"Republic of Mozambique"}},
+	{"NA", "NAM", "Namibia", []string{"Republic of Namibia"}},
+	{"NC", "NCL", "New Caledonia", nil},
+	{"NE", "NER", "Niger", []string{"Republic of the Niger"}},
+	{"NF", "NFK", "Norfolk Island", nil},
+	{"NG", "NGA", "Nigeria", []string{"Federal Republic of Nigeria"}},
+	{"NI", "NIC", "Nicaragua", []string{"Republic of Nicaragua"}},
+	{"NL", "NLD", "Netherlands", []string{"Kingdom of the Netherlands", "Holland", "Nederland"}},
+	{"NO", "NOR", "Norway", []string{"Kingdom of Norway", "Norge"}},
+	{"NP", "NPL", "Nepal", []string{"Federal Democratic Republic of Nepal"}},
+	{"NR", "NRU", "Nauru", []string{"Republic of Nauru"}},
+	{"NU", "NIU", "Niue", nil},
+	{"NZ", "NZL", "New Zealand", nil},
+	{"OM", "OMN", "Oman", []string{"Sultanate of Oman"}},
+	{"PA", "PAN", "Panama", []string{"Republic of Panama"}},
+	{"PE", "PER", "Peru", []string{"Republic of Peru"}},
+	{"PF", "PYF", "French Polynesia", nil},
+	{"PG", "PNG", "Papua New Guinea", []string{"Independent State of Papua New Guinea"}},
+	{"PH", "PHL", "Philippines", []string{"Republic of the Philippines"}},
+	{"PK", "PAK", "Pakistan", []string{"Islamic Republic of Pakistan"}},
+	{"PL", "POL", "Poland", []string{"Republic of Poland", "Polska"}},
+	{"PM", "SPM", "Saint Pierre and Miquelon", nil},
+	{"PN", "PCN", "Pitcairn", nil},
+	{"PR", "PRI", "Puerto Rico", nil},
+	{"PS", "PSE", "Palestine, State of", []string{"the State of Palestine", "Palestine"}},
+	{"PT", "PRT", "Portugal", []string{"Portuguese Republic"}},
+	{"PW", "PLW", "Palau", []string{"Republic of Palau"}},
+	{"PY", "PRY", "Paraguay", []string{"Republic of Paraguay"}},
+	{"QA", "QAT", "Qatar", []string{"State of Qatar"}},
+	{"RE", "REU", "Réunion", []string{"Reunion"}},
+	{"RO", "ROU", "Romania", []string{"România"}},
+	{"RS", "SRB", "Serbia", []string{"Republic of Serbia", "Srbija"}},
+	{"RU", "RUS", "Russian Federation", []string{"Russia", "Rossiya"}},
+	{"RW", "RWA", "Rwanda", []string{"Rwandese Republic"}},
+	{"SA", "SAU", "Saudi Arabia", []string{"Kingdom of Saudi Arabia"}},
+	{"SB", "SLB", "Solomon Islands", nil},
+	{"SC", "SYC", "Seychelles", []string{"Republic of Seychelles"}},
+	{"SD", "SDN", "Sudan", []string{"Republic of the Sudan"}},
+	{"SE", "SWE", "Sweden", []string{"Kingdom of Sweden", "Sverige"}},
+	{"SG", "SGP", "Singapore", []string{"Republic of Singapore"}},
+	{"SH", "SHN", "Saint Helena, Ascension and Tristan da Cunha", nil},
+	{"SI", "SVN", "Slovenia", []string{"Republic of Slovenia", "Slovenija"}},
+	{"SJ", "SJM", "Svalbard and Jan Mayen", nil},
+	{"SK", "SVK", "Slovakia", []string{"Slovak Republic", "Slovensko"}},
+	{"SL", "SLE", "Sierra Leone", []string{"Republic of Sierra Leone"}},
+	{"SM", "SMR", "San Marino", []string{"Republic of San Marino"}},
+	{"SN", "SEN", "Senegal", []string{"Republic of Senegal"}},
+	{"SO", "SOM", "Somalia", []string{"Federal Republic of Somalia"}},
+	{"SR", "SUR", "Suriname", []string{"Republic of Suriname"}},
+	{"SS", "SSD", "South Sudan", []string{"Republic of South Sudan"}},
+	{"ST", "STP", "Sao Tome and Principe", []string{"Democratic Republic of Sao Tome and Principe"}},
+	{"SV", "SLV", "El Salvador", []string{"Republic of El Salvador"}},
+	{"SX", "SXM", "Sint Maarten (Dutch part)", []string{"Sint Maarten"}},
+	{"SY", "SYR", "Syrian Arab Republic", []string{"Syria"}},
+	{"SZ", "SWZ", "Eswatini", []string{"Kingdom of Eswatini", "Swaziland"}},
+	{"TC", "TCA", "Turks and Caicos Islands", nil},
+	{"TD", "TCD", "Chad", []string{"Republic of Chad"}},
+	{"TF", "ATF", "French Southern Territories", nil},
+	{"TG", "TGO", "Togo", []string{"Togolese Republic"}},
+	{"TH", "THA", "Thailand", []string{"Kingdom of Thailand"}},
+	{"TJ", "TJK", "Tajikistan", []string{"Republic of Tajikistan"}},
+	{"TK", "TKL", "Tokelau", nil},
+	{"TL", "TLS", "Timor-Leste", []string{"Democratic Republic of Timor-Leste", "East Timor"}},
+	{"TM", "TKM", "Turkmenistan", nil},
+	{"TN", "TUN", "Tunisia", []string{"Republic of Tunisia"}},
+	{"TO", "TON", "Tonga", []string{"Kingdom of Tonga"}},
+	{"TR", "TUR", "Türkiye", []string{"Republic of Türkiye", "Turkey", "Turkiye", "Republic of Turkiye"}},
+	{"TT", "TTO", "Trinidad and Tobago", []string{"Republic of Trinidad and Tobago"}},
+	{"TV", "TUV", "Tuvalu", nil},
+	{"TW", "TWN", "Taiwan, Province of China", []string{"Taiwan"}},
+	{"TZ", "TZA", "Tanzania, United Republic of", []string{"United Republic of Tanzania", "Tanzania"}},
+	{"UA", "UKR", "Ukraine", []string{"Ukraina"}},
+	{"UG", "UGA", "Uganda", []string{"Republic of Uganda"}},
+	{"UM", "UMI", "United States Minor Outlying Islands", nil},
+	{"US", "USA", "United States", []string{"United States of America"}},
+	{"UY", "URY", "Uruguay", []string{"Eastern Republic of Uruguay"}},
+	{"UZ", "UZB", "Uzbekistan", []string{"Republic of Uzbekistan"}},
+	{"VA", "VAT", "Holy See (Vatican City State)", []string{"Vatican", "Vatican City"}},
+	{"VC", "VCT", "Saint Vincent and the Grenadines", nil},
+	{"VE", "VEN", "Venezuela, Bolivarian Republic of", []string{"Bolivarian Republic of Venezuela", "Venezuela"}},
+	{"VG", "VGB", "Virgin Islands, British", []string{"British Virgin Islands"}},
+	{"VI", "VIR", "Virgin Islands, U.S.", []string{"Virgin Islands of the United States", "US Virgin Islands"}},
+	{"VN", "VNM", "Viet Nam", []string{"Socialist Republic of Viet Nam", "Vietnam"}},
+	{"VU", "VUT", "Vanuatu", []string{"Republic of Vanuatu"}},
+	{"WF", "WLF", "Wallis and Futuna", nil},
+	{"WS", "WSM", "Samoa", []string{"Independent State of Samoa"}},
+	{"YE", "YEM", "Yemen", []string{"Republic of Yemen"}},
+	{"YT", "MYT", "Mayotte", nil},
+	{"ZA", "ZAF", "South Africa", []string{"Republic of South Africa"}},
+	{"ZM", "ZMB", "Zambia", []string{"Republic of Zambia"}},
+	{"ZW", "ZWE", "Zimbabwe", []string{"Republic of Zimbabwe"}},
+}
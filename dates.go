@@ -0,0 +1,89 @@
+package whoishistory
+
+import (
+	"strings"
+	"time"
+)
+
+// RegistryDateLayouts are layouts of dates found in raw WHOIS data
+// tried by ParseRegistryDate after TimeLayouts.
+// Month names are matched regardless of case. Values without time zone are considered UTC.
+var RegistryDateLayouts = []string{
+	"02-Jan-2006",
+	"02-Jan-2006 15:04:05",
+	"02-Jan-2006 15:04:05 MST",
+	"2006.01.02",
+	"2006.01.02 15:04:05",
+	"2006.01.02 15:04:05 MST",
+	"02.01.2006",
+	"02.01.2006 15:04:05",
+	"2006/01/02",
+	"2006/01/02 15:04:05",
+	"2006-01-02 15:04:05-07",
+	"2006-01-02 15:04:05 -0700",
+	"2006-01-02T15:04:05.0Z",
+	"20060102",
+	"02 Jan 2006",
+	"January 02 2006",
+	"Jan 02 2006",
+	time.ANSIC,
+	time.UnixDate,
+	time.RFC1123,
+	time.RFC1123Z,
+}
+
+// ParseRegistryDate parses the date as formatted by registries.
+// Empty value results in zero time.
+func ParseRegistryDate(value string) (Time, error) {
+	value = strings.Join(strings.Fields(value), " ")
+	value = strings.TrimSuffix(value, " (UTC)")
+
+	if t, err := ParseTime(value); err == nil {
+		return t, nil
+	}
+	for _, layout := range RegistryDateLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return Time(t), nil
+		}
+	}
+	return emptyTime, &TimeError{Value: value}
+}
+
+// DateSource tells which field of WhoisRecord a date comes from.
+type DateSource string
+
+// Sources of WhoisRecord dates.
+const (
+	// DateSourceNone means that the date is not available.
+	DateSourceNone DateSource = ""
+	// DateSourceISO8601 means one of *DateISO8601 fields.
+	DateSourceISO8601 DateSource = "iso8601"
+	// DateSourceRaw means one of *DateRaw fields parsed with ParseRegistryDate.
+	DateSourceRaw DateSource = "raw"
+)
+
+// bestDate returns the ISO 8601 date if it's set or the parsed raw date otherwise.
+func bestDate(iso Time, raw string) (Time, DateSource) {
+	if !iso.IsZero() {
+		return iso, DateSourceISO8601
+	}
+	if t, err := ParseRegistryDate(raw); err == nil && !t.IsZero() {
+		return t, DateSourceRaw
+	}
+	return emptyTime, DateSourceNone
+}
+
+// CreatedDate returns the best available creation date of the domain and its source.
+func (r *WhoisRecord) CreatedDate() (Time, DateSource) {
+	return bestDate(r.CreatedDateISO8601, r.CreatedDateRaw)
+}
+
+// UpdatedDate returns the best available update date of the domain and its source.
+func (r *WhoisRecord) UpdatedDate() (Time, DateSource) {
+	return bestDate(r.UpdatedDateISO8601, r.UpdatedDateRaw)
+}
+
+// ExpiresDate returns the best available expiration date of the domain and its source.
+func (r *WhoisRecord) ExpiresDate() (Time, DateSource) {
+	return bestDate(r.ExpiresDateISO8601, r.ExpiresDateRaw)
+}
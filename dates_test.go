@@ -0,0 +1,60 @@
+package whoishistory
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseRegistryDate(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    time.Time
+		wantErr string
+	}{
+		{value: "", want: time.Time{}},
+		{value: "15-jan-2004", want: time.Date(2004, 1, 15, 0, 0, 0, 0, time.UTC)},
+		{value: "15-JAN-2004 10:00:00 UTC", want: time.Date(2004, 1, 15, 10, 0, 0, 0, time.UTC)},
+		{value: "2004.01.15 10:00:00", want: time.Date(2004, 1, 15, 10, 0, 0, 0, time.UTC)},
+		{value: "  2004.01.15  ", want: time.Date(2004, 1, 15, 0, 0, 0, 0, time.UTC)},
+		{value: "15.01.2004", want: time.Date(2004, 1, 15, 0, 0, 0, 0, time.UTC)},
+		{value: "2004/01/15 10:00:00", want: time.Date(2004, 1, 15, 10, 0, 0, 0, time.UTC)},
+		{value: "2004-01-15 10:00:00+02", want: time.Date(2004, 1, 15, 8, 0, 0, 0, time.UTC)},
+		{value: "2004-01-15T10:00:00Z", want: time.Date(2004, 1, 15, 10, 0, 0, 0, time.UTC)},
+		{value: "2004-01-15 10:00:00 (UTC)", want: time.Date(2004, 1, 15, 10, 0, 0, 0, time.UTC)},
+		{value: "20040115", want: time.Date(2004, 1, 15, 0, 0, 0, 0, time.UTC)},
+		{value: "January 15 2004", want: time.Date(2004, 1, 15, 0, 0, 0, 0, time.UTC)},
+		{value: "Thu Jan 15 10:00:00 2004", want: time.Date(2004, 1, 15, 10, 0, 0, 0, time.UTC)},
+		{value: "before Aug-1996", wantErr: `cannot parse time "before Aug-1996"`},
+	}
+	for _, tt := range tests {
+		got, err := ParseRegistryDate(tt.value)
+		checkErr(t, err, tt.wantErr)
+		if !got.Std().Equal(tt.want) {
+			t.Errorf("ParseRegistryDate(%q) = %v, want %v", tt.value, got.Std(), tt.want)
+		}
+	}
+}
+
+func TestWhoisRecordDates(t *testing.T) {
+	iso, err := ParseTime("2010-05-01T00:00:00Z")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rec := &WhoisRecord{
+		CreatedDateISO8601: iso,
+		CreatedDateRaw:     "01-may-2010",
+		UpdatedDateRaw:     "2012.03.04 05:06:07",
+		ExpiresDateRaw:     "unknown",
+	}
+
+	if got, source := rec.CreatedDate(); got != iso || source != DateSourceISO8601 {
+		t.Errorf("CreatedDate() = %v, %q", got, source)
+	}
+	if got, source := rec.UpdatedDate(); !got.Std().Equal(time.Date(2012, 3, 4, 5, 6, 7, 0, time.UTC)) || source != DateSourceRaw {
+		t.Errorf("UpdatedDate() = %v, %q", got, source)
+	}
+	if got, source := rec.ExpiresDate(); !got.IsZero() || source != DateSourceNone {
+		t.Errorf("ExpiresDate() = %v, %q", got, source)
+	}
+}
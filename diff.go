@@ -0,0 +1,250 @@
+package whoishistory
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+)
+
+// ChangeKind is the kind of Change.
+type ChangeKind string
+
+// Kinds of changes.
+const (
+	// ChangeAdded means that the value is set or added to the set.
+	ChangeAdded ChangeKind = "added"
+	// ChangeRemoved means that the value is cleared or removed from the set.
+	ChangeRemoved ChangeKind = "removed"
+	// ChangeModified means that the value is replaced.
+	ChangeModified ChangeKind = "modified"
+)
+
+// Change is a difference of a field of two records.
+type Change struct {
+	// Field is the JSON name of the field like "registrarName".
+	// Contact fields are prefixed with the contact like "registrantContact.email".
+	Field string     `json:"field"`
+	Kind  ChangeKind `json:"kind"`
+	// Old is the old value. For sets it's the removed element.
+	Old string `json:"old,omitempty"`
+	// New is the new value. For sets it's the added element.
+	New string `json:"new,omitempty"`
+}
+
+func (c Change) String() string {
+	switch c.Kind {
+	case ChangeAdded:
+		return "+ " + c.Field + ": " + strconv.Quote(c.New)
+	case ChangeRemoved:
+		return "- " + c.Field + ": " + strconv.Quote(c.Old)
+	}
+	return "~ " + c.Field + ": " + strconv.Quote(c.Old) + " -> " + strconv.Quote(c.New)
+}
+
+// RecordDiff is the difference of two records.
+type RecordDiff struct {
+	Old *WhoisRecord
+	New *WhoisRecord
+	// Changes are ordered as fields of WhoisRecord.
+	Changes []Change
+}
+
+// set fields are compared as sets
+const (
+	fieldNameServers = "nameServers"
+	fieldStatus      = "status"
+)
+
+// namedContact is a contact of the record with its JSON name
+type namedContact struct {
+	name    string
+	contact *Contact
+}
+
+func contactFields(r *WhoisRecord) []namedContact {
+	return []namedContact{
+		{"registrantContact", &r.RegistrantContact},
+		{"administrativeContact", &r.AdministrativeContact},
+		{"technicalContact", &r.TechnicalContact},
+		{"billingContact", &r.BillingContact},
+		{"zoneContact", &r.ZoneContact},
+	}
+}
+
+// Diff compares two records of a domain field by field.
+// NameServers and Status are compared as sets ignoring case and order.
+// Audit dates and text fields restating the structured ones
+// (RawText, CleanText and Contact.RawText) are not compared.
+// Nil record is considered empty.
+func Diff(from, to *WhoisRecord) *RecordDiff {
+	d := &RecordDiff{Old: from, New: to}
+	if from == nil {
+		from = &WhoisRecord{}
+	}
+	if to == nil {
+		to = &WhoisRecord{}
+	}
+
+	d.scalar("domainName", from.DomainName, to.DomainName)
+	d.scalar("domainType", from.DomainType, to.DomainType)
+	d.time("createdDateISO8601", from.CreatedDateISO8601, to.CreatedDateISO8601)
+	d.time("updatedDateISO8601", from.UpdatedDateISO8601, to.UpdatedDateISO8601)
+	d.time("expiresDateISO8601", from.ExpiresDateISO8601, to.ExpiresDateISO8601)
+	d.scalar("createdDateRaw", from.CreatedDateRaw, to.CreatedDateRaw)
+	d.scalar("updatedDateRaw", from.UpdatedDateRaw, to.UpdatedDateRaw)
+	d.scalar("expiresDateRaw", from.ExpiresDateRaw, to.ExpiresDateRaw)
+	d.set(fieldNameServers, from.NameServers, to.NameServers, nameServerKey)
+	d.scalar("whoisServer", from.WhoisServer, to.WhoisServer)
+	d.scalar("registrarName", from.RegistrarName, to.RegistrarName)
+	d.set(fieldStatus, from.Status, to.Status, statusKey)
+
+	newContacts := contactFields(to)
+	for i, c := range contactFields(from) {
+		d.contact(c.name, c.contact, newContacts[i].contact)
+	}
+
+	return d
+}
+
+func (d *RecordDiff) scalar(field, from, to string) {
+	switch {
+	case from == to:
+		return
+	case from == "":
+		d.Changes = append(d.Changes, Change{Field: field, Kind: ChangeAdded, New: to})
+	case to == "":
+		d.Changes = append(d.Changes, Change{Field: field, Kind: ChangeRemoved, Old: from})
+	default:
+		d.Changes = append(d.Changes, Change{Field: field, Kind: ChangeModified, Old: from, New: to})
+	}
+}
+
+func (d *RecordDiff) time(field string, from, to Time) {
+	if from.Std().Equal(to.Std()) {
+		return
+	}
+	d.scalar(field, from.String(), to.String())
+}
+
+func (d *RecordDiff) contact(name string, from, to *Contact) {
+	prefix := name + "."
+	d.scalar(prefix+"name", from.Name, to.Name)
+	d.scalar(prefix+"organization", from.Organization, to.Organization)
+	d.scalar(prefix+"street", from.Street, to.Street)
+	d.scalar(prefix+"city", from.City, to.City)
+	d.scalar(prefix+"state", from.State, to.State)
+	d.scalar(prefix+"postalCode", from.PostalCode, to.PostalCode)
+	d.scalar(prefix+"country", from.Country, to.Country)
+	d.scalar(prefix+"email", from.Email, to.Email)
+	d.scalar(prefix+"telephone", from.Telephone, to.Telephone)
+	d.scalar(prefix+"telephoneExt", from.TelephoneExt, to.TelephoneExt)
+	d.scalar(prefix+"fax", from.Fax, to.Fax)
+	d.scalar(prefix+"faxExt", from.FaxExt, to.FaxExt)
+}
+
+// set adds removed elements in the old order and then added elements in the new order.
+func (d *RecordDiff) set(field string, from, to []string, key func(string) string) {
+	oldKeys := make(map[string]bool, len(from))
+	for _, v := range from {
+		oldKeys[key(v)] = true
+	}
+	newKeys := make(map[string]bool, len(to))
+	for _, v := range to {
+		newKeys[key(v)] = true
+	}
+
+	reported := make(map[string]bool)
+	for _, v := range from {
+		if k := key(v); k != "" && !newKeys[k] && !reported[k] {
+			d.Changes = append(d.Changes, Change{Field: field, Kind: ChangeRemoved, Old: v})
+			reported[k] = true
+		}
+	}
+	for _, v := range to {
+		if k := key(v); k != "" && !oldKeys[k] && !reported[k] {
+			d.Changes = append(d.Changes, Change{Field: field, Kind: ChangeAdded, New: v})
+			reported[k] = true
+		}
+	}
+}
+
+// nameServerKey normalizes the name server for comparison.
+func nameServerKey(ns string) string {
+	return strings.ToLower(strings.TrimRight(strings.TrimSpace(ns), "."))
+}
+
+// statusKey normalizes the status code for comparison.
+// EPP status codes are often followed by the URL explaining them.
+func statusKey(status string) string {
+	fields := strings.Fields(status)
+	if len(fields) == 0 {
+		return ""
+	}
+	return strings.ToLower(fields[0])
+}
+
+// Empty reports whether records are equal.
+func (d *RecordDiff) Empty() bool {
+	return len(d.Changes) == 0
+}
+
+// Fields returns names of changed fields in order without duplicates.
+func (d *RecordDiff) Fields() []string {
+	var fields []string
+	seen := make(map[string]bool)
+	for _, c := range d.Changes {
+		if !seen[c.Field] {
+			seen[c.Field] = true
+			fields = append(fields, c.Field)
+		}
+	}
+	return fields
+}
+
+// String renders changes as text, one change per line.
+func (d *RecordDiff) String() string {
+	var b strings.Builder
+	for _, c := range d.Changes {
+		b.WriteString(c.String())
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
+
+// patchOp is an operation of JSON Patch
+type patchOp struct {
+	Op    string      `json:"op"`
+	Path  string      `json:"path"`
+	Value interface{} `json:"value"`
+}
+
+// JSONPatch renders changes as JSON Patch (RFC 6902) transforming
+// the JSON of the old record into the new one for compared fields.
+// Changed sets are replaced as a whole.
+func (d *RecordDiff) JSONPatch() ([]byte, error) {
+	to := d.New
+	if to == nil {
+		to = &WhoisRecord{}
+	}
+
+	ops := []patchOp{}
+	for _, field := range d.Fields() {
+		op := patchOp{Op: "replace", Path: "/" + strings.Replace(field, ".", "/", -1)}
+		switch field {
+		case fieldNameServers:
+			op.Value = to.NameServers
+		case fieldStatus:
+			op.Value = to.Status
+		default:
+			for _, c := range d.Changes {
+				if c.Field == field {
+					op.Value = c.New
+					break
+				}
+			}
+		}
+		ops = append(ops, op)
+	}
+
+	return json.Marshal(ops)
+}
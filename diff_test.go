@@ -0,0 +1,126 @@
+package whoishistory
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	expires, err := ParseTime("2021-01-01T00:00:00Z")
+	if err != nil {
+		t.Fatal(err)
+	}
+	renewed, err := ParseTime("2022-01-01T00:00:00Z")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	old := &WhoisRecord{
+		DomainName:         "a.test",
+		ExpiresDateISO8601: expires,
+		RegistrarName:      "Registrar A",
+		NameServers:        []string{"NS1.example.com.", "ns2.example.com"},
+		Status:             []string{"clientTransferProhibited https://icann.org/epp#clientTransferProhibited"},
+		RegistrantContact:  Contact{Name: "John", Email: "john@a.test", RawText: "John"},
+		RawText:            "old text",
+	}
+	new := &WhoisRecord{
+		DomainName:         "a.test",
+		ExpiresDateISO8601: renewed,
+		RegistrarName:      "Registrar B",
+		NameServers:        []string{"ns2.example.com", "ns1.example.com", "ns3.example.com"},
+		Status:             []string{"clientTransferProhibited", "clientHold"},
+		RegistrantContact:  Contact{Name: "John", Organization: "A Inc", RawText: "John, A Inc"},
+		RawText:            "new text",
+	}
+
+	d := Diff(old, new)
+
+	want := []Change{
+		{Field: "expiresDateISO8601", Kind: ChangeModified, Old: "2021-01-01T00:00:00+00:00", New: "2022-01-01T00:00:00+00:00"},
+		{Field: "nameServers", Kind: ChangeAdded, New: "ns3.example.com"},
+		{Field: "registrarName", Kind: ChangeModified, Old: "Registrar A", New: "Registrar B"},
+		{Field: "status", Kind: ChangeAdded, New: "clientHold"},
+		{Field: "registrantContact.organization", Kind: ChangeAdded, New: "A Inc"},
+		{Field: "registrantContact.email", Kind: ChangeRemoved, Old: "john@a.test"},
+	}
+	if !reflect.DeepEqual(d.Changes, want) {
+		t.Fatalf("Changes = %+v, want %+v", d.Changes, want)
+	}
+
+	const text = `~ expiresDateISO8601: "2021-01-01T00:00:00+00:00" -> "2022-01-01T00:00:00+00:00"
++ nameServers: "ns3.example.com"
+~ registrarName: "Registrar A" -> "Registrar B"
++ status: "clientHold"
++ registrantContact.organization: "A Inc"
+- registrantContact.email: "john@a.test"
+`
+	if got := d.String(); got != text {
+		t.Errorf("String() = %v", got)
+	}
+
+	patch, err := d.JSONPatch()
+	if err != nil {
+		t.Fatal(err)
+	}
+	const wantPatch = `[{"op":"replace","path":"/expiresDateISO8601","value":"2022-01-01T00:00:00+00:00"},` +
+		`{"op":"replace","path":"/nameServers","value":["ns2.example.com","ns1.example.com","ns3.example.com"]},` +
+		`{"op":"replace","path":"/registrarName","value":"Registrar B"},` +
+		`{"op":"replace","path":"/status","value":["clientTransferProhibited","clientHold"]},` +
+		`{"op":"replace","path":"/registrantContact/organization","value":"A Inc"},` +
+		`{"op":"replace","path":"/registrantContact/email","value":""}]`
+	if string(patch) != wantPatch {
+		t.Errorf("JSONPatch() = %s", patch)
+	}
+
+	if !Diff(old, old).Empty() {
+		t.Error("record differs from itself")
+	}
+}
+
+func TestDiffJSONPatchApplies(t *testing.T) {
+	old := &WhoisRecord{DomainName: "a.test", Status: []string{"ok"}, ZoneContact: Contact{Fax: "1"}}
+	new := &WhoisRecord{DomainName: "a.test", NameServers: []string{"ns1.a.test"}, ZoneContact: Contact{Fax: "2"}}
+
+	patch, err := Diff(old, new).JSONPatch()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ops []patchOp
+	if err := json.Unmarshal(patch, &ops); err != nil {
+		t.Fatal(err)
+	}
+
+	// apply the replace operations to the generic JSON of the old record
+	b, err := json.Marshal(old)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var doc map[string]interface{}
+	if err := json.Unmarshal(b, &doc); err != nil {
+		t.Fatal(err)
+	}
+	for _, op := range ops {
+		switch op.Path {
+		case "/nameServers", "/status":
+			doc[op.Path[1:]] = op.Value
+		case "/zoneContact/fax":
+			doc["zoneContact"].(map[string]interface{})["fax"] = op.Value
+		default:
+			t.Fatalf("unexpected operation %+v", op)
+		}
+	}
+
+	b, err = json.Marshal(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var patched WhoisRecord
+	if err := json.Unmarshal(b, &patched); err != nil {
+		t.Fatal(err)
+	}
+	if !Diff(&patched, new).Empty() {
+		t.Errorf("patched record differs: %v", Diff(&patched, new))
+	}
+}
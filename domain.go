@@ -0,0 +1,321 @@
+package whoishistory
+
+import (
+	"errors"
+	"net/url"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// maxDomainLength and maxLabelLength are limits of DNS names in ASCII form.
+const (
+	maxDomainLength = 253
+	maxLabelLength  = 63
+)
+
+// acePrefix marks labels encoded with punycode
+const acePrefix = "xn--"
+
+// NormalizeDomain converts user input to the domain name sent to the API.
+// It trims whitespace and trailing dots, takes the host of URLs dropping the scheme,
+// path and port, lowercases the name and converts Unicode labels to punycode.
+// Syntactically invalid names are rejected with ArgError matched by ErrInvalidDomain.
+func NormalizeDomain(name string) (string, error) {
+	name = strings.TrimSpace(name)
+
+	if strings.Contains(name, "://") {
+		u, err := url.Parse(name)
+		if err != nil {
+			return "", &ArgError{"name", "is not a valid URL"}
+		}
+		name = u.Hostname()
+	} else {
+		if i := strings.IndexAny(name, "/?#"); i >= 0 {
+			name = name[:i]
+		}
+		if i := strings.LastIndex(name, "@"); i >= 0 {
+			name = name[i+1:]
+		}
+		if i := strings.LastIndex(name, ":"); i >= 0 && isDigits(name[i+1:]) {
+			name = name[:i]
+		}
+	}
+
+	name = strings.ToLower(strings.TrimRight(name, "."))
+	if name == "" {
+		return "", &ArgError{"name", "cannot be empty"}
+	}
+
+	labels := strings.Split(name, ".")
+	for i, label := range labels {
+		ascii, err := labelToASCII(label)
+		if err != nil {
+			return "", &ArgError{"name", err.Error()}
+		}
+		labels[i] = ascii
+	}
+
+	name = strings.Join(labels, ".")
+	if len(name) > maxDomainLength {
+		return "", &ArgError{"name", "is longer than " + strconv.Itoa(maxDomainLength) + " characters"}
+	}
+
+	return name, nil
+}
+
+// DomainToUnicode converts punycode labels of the domain name to Unicode for display.
+// Labels which cannot be decoded are left as is.
+func DomainToUnicode(name string) string {
+	labels := strings.Split(name, ".")
+	for i, label := range labels {
+		if !strings.HasPrefix(strings.ToLower(label), acePrefix) {
+			continue
+		}
+		if decoded, err := punycodeDecode(label[len(acePrefix):]); err == nil {
+			labels[i] = decoded
+		}
+	}
+	return strings.Join(labels, ".")
+}
+
+// UnicodeDomainName returns the domain name of the record for display.
+func (r *WhoisRecord) UnicodeDomainName() string {
+	return DomainToUnicode(r.DomainName)
+}
+
+func isDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// labelToASCII encodes the lowercased label with punycode if needed and checks its syntax.
+func labelToASCII(label string) (string, error) {
+	if label == "" {
+		return "", errors.New("has empty label")
+	}
+
+	ascii := label
+	if !isASCII(label) {
+		if !utf8.ValidString(label) {
+			return "", errors.New("is not valid UTF-8")
+		}
+		for _, c := range label {
+			if unicode.IsSpace(c) || unicode.IsControl(c) || unicode.IsPunct(c) && c != '-' {
+				return "", errors.New("label " + strconv.Quote(label) + " contains invalid character " + strconv.QuoteRune(c))
+			}
+		}
+		encoded, err := punycodeEncode(label)
+		if err != nil {
+			return "", errors.New("label " + strconv.Quote(label) + " " + err.Error())
+		}
+		ascii = acePrefix + encoded
+	} else if strings.HasPrefix(label, acePrefix) {
+		if _, err := punycodeDecode(label[len(acePrefix):]); err != nil {
+			return "", errors.New("label " + strconv.Quote(label) + " " + err.Error())
+		}
+	}
+
+	if len(ascii) > maxLabelLength {
+		return "", errors.New("label " + strconv.Quote(label) + " is longer than " + strconv.Itoa(maxLabelLength) + " characters")
+	}
+	for i := 0; i < len(ascii); i++ {
+		c := ascii[i]
+		if c >= 'a' && c <= 'z' || c >= '0' && c <= '9' || c == '-' {
+			continue
+		}
+		return "", errors.New("label " + strconv.Quote(label) + " contains invalid character " + strconv.QuoteRune(rune(c)))
+	}
+	if ascii[0] == '-' || ascii[len(ascii)-1] == '-' {
+		return "", errors.New("label " + strconv.Quote(label) + " begins or ends with hyphen")
+	}
+
+	return ascii, nil
+}
+
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= utf8.RuneSelf {
+			return false
+		}
+	}
+	return true
+}
+
+// Punycode parameters defined in RFC 3492
+const (
+	punyBase        = 36
+	punyTMin        = 1
+	punyTMax        = 26
+	punySkew        = 38
+	punyDamp        = 700
+	punyInitialBias = 72
+	punyInitialN    = 128
+)
+
+var errPunycode = errors.New("is not valid punycode")
+
+func punyAdapt(delta, numPoints int, first bool) int {
+	if first {
+		delta /= punyDamp
+	} else {
+		delta /= 2
+	}
+	delta += delta / numPoints
+	k := 0
+	for delta > ((punyBase-punyTMin)*punyTMax)/2 {
+		delta /= punyBase - punyTMin
+		k += punyBase
+	}
+	return k + (punyBase-punyTMin+1)*delta/(delta+punySkew)
+}
+
+func punyThreshold(k, bias int) int {
+	t := k - bias
+	if t < punyTMin {
+		return punyTMin
+	}
+	if t > punyTMax {
+		return punyTMax
+	}
+	return t
+}
+
+func punyDigit(d int) byte {
+	if d < 26 {
+		return byte('a' + d)
+	}
+	return byte('0' + d - 26)
+}
+
+func punyValue(c byte) (int, bool) {
+	switch {
+	case c >= 'a' && c <= 'z':
+		return int(c - 'a'), true
+	case c >= 'A' && c <= 'Z':
+		return int(c - 'A'), true
+	case c >= '0' && c <= '9':
+		return int(c-'0') + 26, true
+	}
+	return 0, false
+}
+
+// punycodeEncode encodes the string without the ACE prefix.
+func punycodeEncode(s string) (string, error) {
+	input := []rune(s)
+
+	var out []byte
+	for _, c := range input {
+		if c < utf8.RuneSelf {
+			out = append(out, byte(c))
+		}
+	}
+	basic := len(out)
+	if basic > 0 {
+		out = append(out, '-')
+	}
+
+	n, delta, bias := punyInitialN, 0, punyInitialBias
+	for h := basic; h < len(input); {
+		m := int(unicode.MaxRune) + 1
+		for _, c := range input {
+			if int(c) >= n && int(c) < m {
+				m = int(c)
+			}
+		}
+
+		delta += (m - n) * (h + 1)
+		if delta < 0 {
+			return "", errors.New("is too long")
+		}
+		n = m
+
+		for _, c := range input {
+			if int(c) < n {
+				delta++
+			}
+			if int(c) != n {
+				continue
+			}
+			q := delta
+			for k := punyBase; ; k += punyBase {
+				t := punyThreshold(k, bias)
+				if q < t {
+					break
+				}
+				out = append(out, punyDigit(t+(q-t)%(punyBase-t)))
+				q = (q - t) / (punyBase - t)
+			}
+			out = append(out, punyDigit(q))
+			bias = punyAdapt(delta, h+1, h == basic)
+			delta = 0
+			h++
+		}
+		delta++
+		n++
+	}
+
+	return string(out), nil
+}
+
+// punycodeDecode decodes the string without the ACE prefix.
+func punycodeDecode(s string) (string, error) {
+	var output []rune
+	pos := 0
+	if b := strings.LastIndexByte(s, '-'); b >= 0 {
+		for i := 0; i < b; i++ {
+			if s[i] >= utf8.RuneSelf {
+				return "", errPunycode
+			}
+			output = append(output, rune(s[i]))
+		}
+		pos = b + 1
+	}
+
+	n, i, bias := punyInitialN, 0, punyInitialBias
+	for pos < len(s) {
+		oldi, w := i, 1
+		for k := punyBase; ; k += punyBase {
+			if pos >= len(s) {
+				return "", errPunycode
+			}
+			digit, ok := punyValue(s[pos])
+			pos++
+			if !ok {
+				return "", errPunycode
+			}
+			i += digit * w
+			if i < 0 || i > unicode.MaxRune*punyBase {
+				return "", errPunycode
+			}
+			t := punyThreshold(k, bias)
+			if digit < t {
+				break
+			}
+			w *= punyBase - t
+		}
+
+		length := len(output) + 1
+		bias = punyAdapt(i-oldi, length, oldi == 0)
+		n += i / length
+		i %= length
+		if n > unicode.MaxRune || n >= 0xD800 && n <= 0xDFFF {
+			return "", errPunycode
+		}
+
+		output = append(output, 0)
+		copy(output[i+1:], output[i:])
+		output[i] = rune(n)
+		i++
+	}
+
+	return string(output), nil
+}
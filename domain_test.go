@@ -0,0 +1,109 @@
+package whoishistory
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestNormalizeDomain(t *testing.T) {
+	tests := []struct {
+		name    string
+		want    string
+		wantErr string
+	}{
+		{name: " WhoisXMLAPI.com. ", want: "whoisxmlapi.com"},
+		{name: "https://user@www.Example.com:8080/path?q=1#top", want: "www.example.com"},
+		{name: "example.com:443/path", want: "example.com"},
+		{name: "Bücher.example", want: "xn--bcher-kva.example"},
+		{name: "例え.テスト", want: "xn--r8jz45g.xn--zckzah"},
+		{name: "пример.рф", want: "xn--e1afmkfd.xn--p1ai"},
+		{name: "xn--fiqs8s", want: "xn--fiqs8s"},
+		{name: "  ", wantErr: `invalid argument: "name" cannot be empty`},
+		{name: "a..b", wantErr: `invalid argument: "name" has empty label`},
+		{name: "-a.com", wantErr: `invalid argument: "name" label "-a" begins or ends with hyphen`},
+		{name: "a_b.com", wantErr: `invalid argument: "name" label "a_b" contains invalid character '_'`},
+		{name: "a b.com", wantErr: `invalid argument: "name" label "a b" contains invalid character ' '`},
+		{name: "xn--a-.com", wantErr: `invalid argument: "name" label "xn--a-" begins or ends with hyphen`},
+		{name: "xn--ab!.com", wantErr: `invalid argument: "name" label "xn--ab!" is not valid punycode`},
+		{
+			name:    "abcdefghijklmnopqrstuvwxyzabcdefghijklmnopqrstuvwxyzabcdefghijklm.com",
+			wantErr: `invalid argument: "name" label "abcdefghijklmnopqrstuvwxyzabcdefghijklmnopqrstuvwxyzabcdefghijklm" is longer than 63 characters`,
+		},
+	}
+	for _, tt := range tests {
+		got, err := NormalizeDomain(tt.name)
+		checkErr(t, err, tt.wantErr)
+		if got != tt.want {
+			t.Errorf("NormalizeDomain(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+		if err != nil && !errors.Is(err, ErrInvalidDomain) {
+			t.Errorf("NormalizeDomain(%q) error is not ErrInvalidDomain", tt.name)
+		}
+	}
+}
+
+func TestDomainToUnicode(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{name: "xn--bcher-kva.example", want: "bücher.example"},
+		{name: "XN--R8JZ45G.xn--zckzah", want: "例え.テスト"},
+		{name: "xn--e1afmkfd.xn--p1ai", want: "пример.рф"},
+		{name: "xn--ab!.com", want: "xn--ab!.com"},
+		{name: "example.com", want: "example.com"},
+	}
+	for _, tt := range tests {
+		if got := DomainToUnicode(tt.name); got != tt.want {
+			t.Errorf("DomainToUnicode(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+
+	rec := &WhoisRecord{DomainName: "xn--mnchen-3ya.de"}
+	if got := rec.UnicodeDomainName(); got != "münchen.de" {
+		t.Errorf("UnicodeDomainName() = %q", got)
+	}
+}
+
+func TestAPI_NormalizeDomain(t *testing.T) {
+	server := whoisServer(`{"recordsCount":1,"records":[]}`, "")
+	defer server.Close()
+
+	api := newAPI(server, pathWhoisResponseOK)
+
+	_, resp, err := api.Preview(context.Background(), "https://Bücher.example/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := resp.Request.URL.Query().Get("domainName"); got != "xn--bcher-kva.example" {
+		t.Errorf("domainName = %v", got)
+	}
+
+	_, _, err = api.Preview(context.Background(), "a..b")
+	if !errors.Is(err, ErrInvalidDomain) {
+		t.Errorf("error = %v", err)
+	}
+}
+
+func TestAPI_WhoisGetNormalizeDomain(t *testing.T) {
+	server := whoisServer(`{"WhoisRecord":{"domainName":"xn--bcher-kva.example"}}`, "")
+	defer server.Close()
+
+	api := newAPI(server, pathWhoisResponseOK)
+
+	for _, name := range []string{"Bücher.EXAMPLE", "BÜCHER.example."} {
+		_, resp, err := api.Get(context.Background(), name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := resp.Request.URL.Query().Get("domainName"); got != "xn--bcher-kva.example" {
+			t.Errorf("%s: domainName = %v", name, got)
+		}
+	}
+
+	_, resp, err := api.Get(context.Background(), "a..b")
+	if !errors.Is(err, ErrInvalidDomain) || resp != nil {
+		t.Errorf("error = %v", err)
+	}
+}
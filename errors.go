@@ -0,0 +1,103 @@
+package whoishistory
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+)
+
+// Sentinel errors classify failures reported by the API.
+// Use errors.Is to check them on errors returned by the services.
+var (
+	// ErrBadRequest means that the request is malformed.
+	ErrBadRequest = errors.New("bad request")
+	// ErrUnauthorized means that the API key is missing or invalid.
+	ErrUnauthorized = errors.New("unauthorized")
+	// ErrInsufficientCredits means that the account has no credits left
+	// or the access to the API is restricted.
+	ErrInsufficientCredits = errors.New("insufficient credits")
+	// ErrRateLimited means that too many requests were sent.
+	ErrRateLimited = errors.New("rate limited")
+	// ErrInvalidDomain means that the domain name was rejected.
+	ErrInvalidDomain = errors.New("invalid domain name")
+	// ErrServer means that the API failed to process the request.
+	ErrServer = errors.New("server error")
+)
+
+// errorForCode maps HTTP status code or API error code to a sentinel error.
+// It returns nil for unknown codes.
+func errorForCode(code int) error {
+	switch {
+	case code == http.StatusBadRequest:
+		return ErrBadRequest
+	case code == http.StatusUnauthorized:
+		return ErrUnauthorized
+	case code == http.StatusPaymentRequired || code == http.StatusForbidden:
+		return ErrInsufficientCredits
+	case code == http.StatusTooManyRequests:
+		return ErrRateLimited
+	case code == http.StatusUnprocessableEntity:
+		return ErrInvalidDomain
+	case code == http.StatusRequestTimeout || code >= 500 && code <= 599:
+		return ErrServer
+	}
+	return nil
+}
+
+// IsRetryable reports whether the request failed with err may succeed if repeated:
+// rate limiting, server errors and transient transport errors.
+func IsRetryable(err error) bool {
+	return errors.Is(err, ErrRateLimited) || errors.Is(err, ErrServer) || IsRetryableError(err)
+}
+
+// IsQuota reports whether err is caused by exhausted credits or rate limits.
+func IsQuota(err error) bool {
+	return errors.Is(err, ErrInsufficientCredits) || errors.Is(err, ErrRateLimited)
+}
+
+// ErrorKind returns a short name of the error class suitable for metrics labels.
+// It returns empty string for nil error.
+func ErrorKind(err error) string {
+	switch {
+	case err == nil:
+		return ""
+	case errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded):
+		return "canceled"
+	case errors.Is(err, ErrUnauthorized):
+		return "unauthorized"
+	case errors.Is(err, ErrInsufficientCredits):
+		return "insufficient_credits"
+	case errors.Is(err, ErrRateLimited):
+		return "rate_limited"
+	case errors.Is(err, ErrInvalidDomain):
+		return "invalid_domain"
+	case errors.Is(err, ErrBadRequest):
+		return "bad_request"
+	case errors.Is(err, ErrServer):
+		return "server"
+	case errors.Is(err, ErrBudgetExceeded):
+		return "budget"
+	case errors.Is(err, ErrResponseTooLarge):
+		return "too_large"
+	}
+
+	var argErr *ArgError
+	var apiErr *ErrorMessage
+	var respErr *ErrorResponse
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	switch {
+	case errors.As(err, &argErr):
+		return "argument"
+	case errors.As(err, &apiErr):
+		return "api"
+	case errors.As(err, &respErr):
+		return "http"
+	case errors.As(err, &syntaxErr) || errors.As(err, &typeErr):
+		return "decode"
+	case IsRetryableError(err):
+		return "transport"
+	}
+	return "other"
+}
@@ -0,0 +1,112 @@
+package whoishistory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+func TestErrorClassification(t *testing.T) {
+	sentinels := []error{
+		ErrBadRequest,
+		ErrUnauthorized,
+		ErrInsufficientCredits,
+		ErrRateLimited,
+		ErrInvalidDomain,
+		ErrServer,
+	}
+
+	tests := []struct {
+		name      string
+		err       error
+		want      error
+		retryable bool
+		quota     bool
+	}{
+		{
+			name: "api code unauthorized",
+			err:  &ErrorMessage{Code: 401, Message: "invalid key"},
+			want: ErrUnauthorized,
+		},
+		{
+			name:  "api code credits",
+			err:   &ErrorMessage{Code: 403, Message: "access restricted"},
+			want:  ErrInsufficientCredits,
+			quota: true,
+		},
+		{
+			name:      "status rate limited",
+			err:       &ErrorResponse{Response: &http.Response{StatusCode: 429}},
+			want:      ErrRateLimited,
+			retryable: true,
+			quota:     true,
+		},
+		{
+			name: "status invalid domain",
+			err:  &ErrorResponse{Response: &http.Response{StatusCode: 422}},
+			want: ErrInvalidDomain,
+		},
+		{
+			name:      "wrapped server error",
+			err:       fmt.Errorf("wrapped: %w", &ErrorResponse{Response: &http.Response{StatusCode: 503}}),
+			want:      ErrServer,
+			retryable: true,
+		},
+		{
+			name: "empty name",
+			err:  &ArgError{"name", "cannot be empty"},
+			want: ErrInvalidDomain,
+		},
+		{
+			name: "unknown code",
+			err:  &ErrorMessage{Code: 123, Message: "test error"},
+			want: nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, s := range sentinels {
+				if got := errors.Is(tt.err, s); got != (s == tt.want) {
+					t.Errorf("errors.Is(%v) = %v", s, got)
+				}
+			}
+			if got := IsRetryable(tt.err); got != tt.retryable {
+				t.Errorf("IsRetryable() = %v, want %v", got, tt.retryable)
+			}
+			if got := IsQuota(tt.err); got != tt.quota {
+				t.Errorf("IsQuota() = %v, want %v", got, tt.quota)
+			}
+		})
+	}
+}
+
+func TestAPIErrorAs(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		_, _ = w.Write([]byte(`{"code":401,"messages":"invalid key"}`))
+	}))
+	defer server.Close()
+
+	apiURL, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	api := NewClient(apiKey, ClientParams{HTTPClient: server.Client(), HistoricBaseURL: apiURL})
+
+	_, _, err = api.Preview(context.Background(), "whoisxmlapi.com")
+
+	var apiErr *ErrorMessage
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("errors.As(*ErrorMessage) failed for %v", err)
+	}
+	if apiErr.Code != 401 || apiErr.Message != "invalid key" {
+		t.Errorf("got = %+v", apiErr)
+	}
+	if !errors.Is(err, ErrUnauthorized) {
+		t.Errorf("errors.Is(ErrUnauthorized) failed for %v", err)
+	}
+}
@@ -26,6 +26,10 @@ func HistoricWhoisPreview(apiKey string) {
 			log.Println(apiErr.Code)
 			log.Println(apiErr.Message)
 		}
+		// Check the kind of the error
+		if errors.Is(err, whoishistory.ErrInsufficientCredits) {
+			log.Fatal("top up your balance")
+		}
 		log.Fatal(err)
 	}
 
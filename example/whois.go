@@ -0,0 +1,29 @@
+package example
+
+import (
+	"context"
+	"github.com/whois-api-llc/whois-history-go"
+	"log"
+)
+
+// WhoisGet is an example of WHOIS API usage
+func WhoisGet(apiKey string) {
+
+	client := whoishistory.NewBasicClient(apiKey)
+
+	current, _, err := client.WhoisService.Get(context.Background(), "whoisxmlapi.com")
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	records, _, err := client.HistoricService.Purchase(context.Background(), "whoisxmlapi.com")
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	for _, rec := range records {
+		if rec.RegistrarName != current.RegistrarName {
+			log.Println("registrar changed since", rec.Audit.UpdatedDate, rec.RegistrarName)
+		}
+	}
+}
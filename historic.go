@@ -1,12 +1,16 @@
 package whoishistory
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"io/ioutil"
 	"net/http"
 	"net/url"
-	"strings"
+	"time"
 )
 
 const defaultHistoricWhoisURL = `https://whois-history.whoisxmlapi.com/api/v1`
@@ -17,12 +21,45 @@ type HistoricService interface {
 	Preview(ctx context.Context, name string, opts ...Option) (int, *Response, error)
 }
 
+// HistoricStreamer is implemented by HistoricService implementations
+// which decode purchased records one by one.
+type HistoricStreamer interface {
+	PurchaseEach(ctx context.Context, name string, fn func(*WhoisRecord) error, opts ...Option) (int, *Response, error)
+}
+
+// PurchaseEach passes purchased records of the HistoricService to fn one by one.
+// Records are streamed if the service implements HistoricStreamer
+// and purchased at once otherwise.
+func (c *Client) PurchaseEach(ctx context.Context, name string, fn func(*WhoisRecord) error, opts ...Option) (int, *Response, error) {
+	return purchaseEach(ctx, c.HistoricService, name, fn, opts...)
+}
+
 type historicServiceOp struct {
 	client  *Client
 	baseURL *url.URL
 }
 
 var _ HistoricService = &historicServiceOp{}
+var _ HistoricStreamer = &historicServiceOp{}
+
+// purchaseEach streams records of the service if it implements HistoricStreamer.
+// Otherwise it passes records returned by Purchase to fn.
+func purchaseEach(ctx context.Context, service HistoricService, name string, fn func(*WhoisRecord) error, opts ...Option) (int, *Response, error) {
+	if streamer, ok := service.(HistoricStreamer); ok {
+		return streamer.PurchaseEach(ctx, name, fn, opts...)
+	}
+
+	records, resp, err := service.Purchase(ctx, name, opts...)
+	if err != nil {
+		return 0, resp, err
+	}
+	for i, rec := range records {
+		if err := fn(rec); err != nil {
+			return i + 1, resp, err
+		}
+	}
+	return len(records), resp, nil
+}
 
 func (service *historicServiceOp) newRequest() (*http.Request, error) {
 
@@ -49,66 +86,445 @@ type historicResponse struct {
 	Message      string         `json:"messages"`
 }
 
-func (service *historicServiceOp) request(ctx context.Context, purchase bool, name string, opts ...Option) (*historicResponse, *Response, error) {
-	if name == "" {
-		return nil, nil, &ArgError{"name", "cannot be empty"}
+func (service *historicServiceOp) request(ctx context.Context, purchase bool, name string, onRecord func(*WhoisRecord) error, opts ...Option) (*historicResponse, *Response, error) {
+
+	outcome := &Outcome{Mode: modePreview, Domain: name}
+	if purchase {
+		outcome.Mode = modePurchase
+	}
+
+	spanName := SpanPreview
+	if purchase {
+		spanName = SpanPurchase
+	}
+	ctx, span := startSpan(ctx, service.client.tracer, spanName)
+	defer span.End()
+	span.SetAttribute("domain", name)
+	span.SetAttribute("mode", outcome.Mode)
+
+	start := time.Now()
+
+	logger := service.client.logger
+	if logger != nil {
+		logRequestStart(logger, outcome, opts)
+	}
+
+	response, resp, err := service.send(ctx, outcome, onRecord, opts...)
+	if resp != nil && outcome.Query != nil {
+		if q, qerr := ParseQuery(outcome.Query); qerr == nil {
+			resp.Query = &q
+		}
+	}
+
+	outcome.Response = resp
+	outcome.Err = err
+	if response != nil {
+		outcome.RecordsCount = response.RecordsCount
+		outcome.Records = response.Records
+	}
+
+	for _, hook := range service.client.hooks {
+		hook(ctx, outcome)
+	}
+
+	duration := time.Since(start)
+	if logger != nil {
+		logRequestFinish(logger, outcome, opts, duration)
+	}
+	if metrics := service.client.metrics; metrics != nil {
+		metrics.ObserveRequest(requestMetrics(outcome, duration))
+	}
+
+	traceOutcome(span, outcome)
+
+	if outcome.Err != nil {
+		return nil, outcome.Response, outcome.Err
+	}
+	if response == nil {
+		response = &historicResponse{}
+	}
+	response.RecordsCount = outcome.RecordsCount
+	response.Records = outcome.Records
+
+	return response, outcome.Response, nil
+}
+
+// build creates the request filling the query of the outcome.
+// It returns the cache control value removed from the query.
+func (service *historicServiceOp) build(outcome *Outcome, opts []Option) (*http.Request, string, error) {
+	name, err := NormalizeDomain(outcome.Domain)
+	if err != nil {
+		return nil, "", err
 	}
 
 	req, err := service.newRequest()
 	if err != nil {
-		return nil, nil, err
+		return nil, "", err
 	}
 
 	q := req.URL.Query()
 	q.Set("domainName", name)
-	if purchase {
-		q.Set("mode", "purchase")
-	} else {
-		q.Set("mode", "preview")
-	}
+	q.Set("mode", outcome.Mode)
 
 	for _, opt := range opts {
 		opt(q)
 	}
 
+	cacheControl := q.Get(cacheParam)
+	q.Del(cacheParam)
+
+	skipValidation := q.Get(validateParam) != ""
+	q.Del(validateParam)
+	if !skipValidation {
+		if err := validateValues(q, time.Now()); err != nil {
+			return nil, "", err
+		}
+	}
+
 	req.URL.RawQuery = q.Encode()
+	outcome.Query = outcomeQuery(q)
+
+	return req, cacheControl, nil
+}
+
+// send makes the request filling the query of the outcome.
+// If onRecord is not nil then records are passed to it as they are decoded
+// instead of being collected into the response.
+func (service *historicServiceOp) send(ctx context.Context, outcome *Outcome, onRecord func(*WhoisRecord) error, opts ...Option) (*historicResponse, *Response, error) {
+
+	tracer := service.client.tracer
+
+	_, span := startSpan(ctx, tracer, SpanBuild)
+	req, cacheControl, err := service.build(outcome, opts)
+	span.RecordError(err)
+	span.End()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	cache := service.client.cache
+	var key string
+	if cache != nil && cacheControl != cacheBypass {
+		key = cacheKey(req.URL.Query().Get("domainName"), req.URL.Query())
+	}
+
+	if key != "" && cacheControl != cacheRefresh {
+		if cached, ok := cache.Get(key); ok {
+			resp := cachedResponse(req)
+			response, err := service.decode(ctx, bytes.NewReader(cached), onRecord)
+			if err != nil {
+				return nil, resp, err
+			}
+			return response, resp, nil
+		}
+	}
+	if cacheControl == cacheOnly {
+		return nil, nil, errCacheMiss
+	}
+
+	// streamed responses are not cached since that would buffer the whole body
+	if onRecord != nil {
+		key = ""
+	}
+
+	httpCtx, span := startSpan(ctx, tracer, SpanHTTP)
+	if tracer != nil {
+		tracer.Inject(httpCtx, req.Header)
+	}
 
-	var b strings.Builder
-	resp, err := service.client.Do(ctx, req, &b)
+	var response *historicResponse
+	var body *bytes.Buffer
+	resp, err := service.client.do(httpCtx, req, func(resp *Response, r io.Reader) error {
+		if c := resp.StatusCode; c < 200 || c > 299 {
+			b, err := ioutil.ReadAll(r)
+			if err != nil {
+				return err
+			}
+			return errorResponse(resp.Response, b)
+		}
+
+		if key != "" {
+			body = &bytes.Buffer{}
+			r = io.TeeReader(r, body)
+		}
+
+		var err error
+		response, err = service.decode(ctx, r, onRecord)
+		return err
+	})
+	if resp != nil {
+		span.SetAttribute("http.status_code", resp.StatusCode)
+		span.SetAttribute("attempts", resp.Attempts)
+	}
+	span.RecordError(err)
+	span.End()
 	if err != nil {
 		return nil, resp, err
 	}
 
-	respErr := checkResponse(resp.Response)
+	if body != nil {
+		cache.Set(key, body.Bytes())
+	}
+
+	return response, resp, nil
+}
+
+// cached reports whether the response of the request is in the cache.
+// It's used by the budget to let cached purchases through without Preview.
+func (service *historicServiceOp) cached(purchase bool, name string, opts []Option) bool {
+	cache := service.client.cache
+	if cache == nil {
+		return false
+	}
+
+	outcome := &Outcome{Mode: modePreview, Domain: name}
+	if purchase {
+		outcome.Mode = modePurchase
+	}
+	req, cacheControl, err := service.build(outcome, opts)
+	if err != nil || cacheControl == cacheBypass || cacheControl == cacheRefresh {
+		return false
+	}
+
+	_, ok := cache.Get(cacheKey(req.URL.Query().Get("domainName"), req.URL.Query()))
+	return ok
+}
+
+// errorResponse creates ErrorResponse with the API error message decoded from the body.
+func errorResponse(r *http.Response, body []byte) *ErrorResponse {
+	respErr := newErrorResponse(r, string(body))
 
 	response := historicResponse{}
+	err := json.Unmarshal(body, &response)
+	if err == nil && (response.Message != "" || response.Code != 0) {
+		respErr.APIError = &ErrorMessage{
+			Code:    response.Code,
+			Message: response.Message,
+		}
+	}
 
-	err = json.NewDecoder(strings.NewReader(b.String())).Decode(&response)
+	return respErr
+}
+
+// decode reads the successful response within the decode span.
+func (service *historicServiceOp) decode(ctx context.Context, r io.Reader, onRecord func(*WhoisRecord) error) (*historicResponse, error) {
+	_, span := startSpan(ctx, service.client.tracer, SpanDecode)
+	defer span.End()
+
+	rd := recordDecoder{textLimits: service.client.textLimits, lenientTimes: service.client.lenientTimes}
+	response, err := decodeHistoric(r, rd, onRecord)
+	span.RecordError(err)
 	if err != nil {
-		if respErr != nil {
-			return nil, resp, err
-		}
-		return nil, resp, fmt.Errorf("cannot parse response: %w", err)
+		return nil, err
 	}
+	span.SetAttribute("records", response.RecordsCount)
 
 	if response.Message != "" || response.Code != 0 {
-		return nil, resp, ErrorMessage{
+		return nil, &ErrorMessage{
 			Code:    response.Code,
 			Message: response.Message,
 		}
 	}
 
-	if respErr != nil {
-		return nil, resp, err
+	return response, nil
+}
+
+// callbackError wraps errors returned by record callbacks
+// to tell them apart from parse errors.
+type callbackError struct {
+	err error
+}
+
+func (e callbackError) Error() string {
+	return e.err.Error()
+}
+
+// decodeHistoric decodes the response object reading records one by one.
+// If onRecord is nil then records are collected into the response.
+func decodeHistoric(r io.Reader, rd recordDecoder, onRecord func(*WhoisRecord) error) (*historicResponse, error) {
+	response, err := decodeHistoricStream(json.NewDecoder(r), rd, onRecord)
+	if err != nil {
+		if cbErr, ok := err.(callbackError); ok {
+			return nil, cbErr.err
+		}
+		return nil, fmt.Errorf("cannot parse response: %w", err)
+	}
+	return response, nil
+}
+
+func decodeHistoricStream(dec *json.Decoder, rd recordDecoder, onRecord func(*WhoisRecord) error) (*historicResponse, error) {
+	response := &historicResponse{}
+
+	if err := expectDelim(dec, '{'); err != nil {
+		return nil, err
+	}
+
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+
+		var dst interface{}
+		switch tok {
+		case "recordsCount":
+			dst = &response.RecordsCount
+		case "code":
+			dst = &response.Code
+		case "messages":
+			dst = &response.Message
+		case "records":
+			if err := decodeRecords(dec, response, rd, onRecord); err != nil {
+				return nil, err
+			}
+			continue
+		default:
+			dst = &json.RawMessage{}
+		}
+
+		if err := dec.Decode(dst); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := expectDelim(dec, '}'); err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+// decodeRecords decodes the array of records. Null is treated as an empty array.
+func decodeRecords(dec *json.Decoder, response *historicResponse, rd recordDecoder, onRecord func(*WhoisRecord) error) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	if tok == nil {
+		return nil
+	}
+	if d, ok := tok.(json.Delim); !ok || d != '[' {
+		return fmt.Errorf("unexpected %v instead of records array", tok)
 	}
 
-	return &response, resp, nil
+	for dec.More() {
+		rec, err := rd.decode(dec)
+		if err != nil {
+			return err
+		}
+		if onRecord == nil {
+			response.Records = append(response.Records, rec)
+			continue
+		}
+		if err := onRecord(rec); err != nil {
+			return callbackError{err}
+		}
+	}
+
+	return expectDelim(dec, ']')
+}
+
+// recordDecoder decodes records according to the client parameters.
+type recordDecoder struct {
+	textLimits   *TextLimits
+	lenientTimes bool
+}
+
+// decode decodes the next record and trims its text fields.
+func (rd recordDecoder) decode(dec *json.Decoder) (*WhoisRecord, error) {
+	var rec *WhoisRecord
+	if rd.lenientTimes {
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); err != nil {
+			return nil, err
+		}
+		var err error
+		rec, err = decodeRecordLenient(raw)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		rec = &WhoisRecord{}
+		if err := dec.Decode(rec); err != nil {
+			return nil, err
+		}
+	}
+
+	rd.textLimits.apply(rec)
+	return rec, nil
+}
+
+// decodeRecordLenient decodes the record skipping time values which cannot be parsed.
+// Skipped values are listed in WhoisRecord.InvalidTimes.
+func decodeRecordLenient(raw json.RawMessage) (*WhoisRecord, error) {
+	rec := &WhoisRecord{}
+	err := json.Unmarshal(raw, rec)
+	var timeErr *TimeError
+	if err == nil || !errors.As(err, &timeErr) {
+		return rec, err
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &fields); err != nil {
+		return nil, err
+	}
+
+	invalid := dropInvalidTimes(fields, "", "createdDateISO8601", "updatedDateISO8601", "expiresDateISO8601")
+
+	var audit map[string]json.RawMessage
+	if json.Unmarshal(fields["audit"], &audit) == nil && audit != nil {
+		if auditInvalid := dropInvalidTimes(audit, "audit.", "createdDate", "updatedDate"); auditInvalid != nil {
+			invalid = append(invalid, auditInvalid...)
+			if fields["audit"], err = json.Marshal(audit); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	b, err := json.Marshal(fields)
+	if err != nil {
+		return nil, err
+	}
+	rec = &WhoisRecord{}
+	if err := json.Unmarshal(b, rec); err != nil {
+		return nil, err
+	}
+	rec.InvalidTimes = invalid
+
+	return rec, nil
+}
+
+// dropInvalidTimes removes time values which cannot be parsed from fields.
+func dropInvalidTimes(fields map[string]json.RawMessage, prefix string, keys ...string) []InvalidTime {
+	var invalid []InvalidTime
+	for _, key := range keys {
+		var value string
+		if json.Unmarshal(fields[key], &value) != nil {
+			continue
+		}
+		if _, err := ParseTime(value); err != nil {
+			invalid = append(invalid, InvalidTime{Field: prefix + key, Value: value})
+			delete(fields, key)
+		}
+	}
+	return invalid
+}
+
+func expectDelim(dec *json.Decoder, delim json.Delim) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	if d, ok := tok.(json.Delim); !ok || d != delim {
+		return fmt.Errorf("unexpected %v instead of %v", tok, delim)
+	}
+	return nil
 }
 
 // Purchase returns the slice of records.
 func (service *historicServiceOp) Purchase(ctx context.Context, name string, opts ...Option) ([]*WhoisRecord, *Response, error) {
 
-	response, resp, err := service.request(ctx, true, name, opts...)
+	response, resp, err := service.request(ctx, true, name, nil, opts...)
 	if err != nil {
 		return nil, resp, err
 	}
@@ -116,10 +532,30 @@ func (service *historicServiceOp) Purchase(ctx context.Context, name string, opt
 	return response.Records, resp, nil
 }
 
+// PurchaseEach passes records to fn one by one as they are decoded from the response,
+// so memory usage doesn't depend on the number of records. Responses are served
+// from the cache but fresh responses are not stored in it. It returns the number
+// of records passed to fn. If fn returns an error then reading stops and the error is returned.
+func (service *historicServiceOp) PurchaseEach(ctx context.Context, name string, fn func(*WhoisRecord) error, opts ...Option) (int, *Response, error) {
+
+	n := 0
+	count := func(rec *WhoisRecord) error {
+		n++
+		return fn(rec)
+	}
+
+	_, resp, err := service.request(ctx, true, name, count, opts...)
+	if err != nil {
+		return n, resp, err
+	}
+
+	return n, resp, nil
+}
+
 // Preview returns the number of records. No credits deducted.
 func (service *historicServiceOp) Preview(ctx context.Context, name string, opts ...Option) (int, *Response, error) {
 
-	response, resp, err := service.request(ctx, false, name, opts...)
+	response, resp, err := service.request(ctx, false, name, nil, opts...)
 	if err != nil {
 		return 0, resp, err
 	}
@@ -136,3 +572,8 @@ type ArgError struct {
 func (a *ArgError) Error() string {
 	return `invalid argument: "` + a.Name + `" ` + a.Message
 }
+
+// Is reports whether the domain name argument is invalid.
+func (a *ArgError) Is(target error) bool {
+	return target == ErrInvalidDomain && a.Name == "name"
+}
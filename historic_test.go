@@ -0,0 +1,176 @@
+package whoishistory
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestAPI_PurchaseEach(t *testing.T) {
+	const resp = `{"recordsCount":3,"extra":{"a":[1,2]},"records":[{"domainName":"a.test"},{"domainName":"b.test"},{"domainName":"c.test"}]}`
+
+	flushed := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		i := strings.Index(resp, `{"domainName":"b.test"}`)
+		_, _ = w.Write([]byte(resp[:i]))
+		w.(http.Flusher).Flush()
+		<-flushed
+		_, _ = w.Write([]byte(resp[i:]))
+	}))
+	defer server.Close()
+
+	api := newAPI(server, "")
+
+	var names []string
+	n, _, err := api.PurchaseEach(context.Background(), "test.test", func(rec *WhoisRecord) error {
+		if len(names) == 0 {
+			// the first record is delivered before the rest of the body is sent
+			close(flushed)
+		}
+		names = append(names, rec.DomainName)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 3 || strings.Join(names, ",") != "a.test,b.test,c.test" {
+		t.Errorf("PurchaseEach() = %d, records %v", n, names)
+	}
+}
+
+func TestAPI_PurchaseEachStop(t *testing.T) {
+	const resp = `{"recordsCount":3,"records":[{"domainName":"a.test"},{"domainName":"b.test"},{"domainName":"c.test"}]}`
+
+	server := whoisServer(resp, "")
+	defer server.Close()
+
+	errStop := errors.New("stop")
+	api := newAPI(server, pathWhoisResponseOK)
+
+	n, resp2, err := api.PurchaseEach(context.Background(), "test.test", func(rec *WhoisRecord) error {
+		if rec.DomainName == "b.test" {
+			return errStop
+		}
+		return nil
+	})
+	if err != errStop {
+		t.Fatalf("error = %v, want %v", err, errStop)
+	}
+	if n != 2 || resp2 == nil {
+		t.Errorf("PurchaseEach() = %d, %v", n, resp2)
+	}
+
+	api = newAPI(server, pathWhoisResponsePartial1)
+	n, _, err = api.PurchaseEach(context.Background(), "test.test", func(rec *WhoisRecord) error {
+		return nil
+	})
+	checkErr(t, err, "cannot parse response: unexpected EOF")
+	if n != 2 {
+		t.Errorf("partial response delivered %d records", n)
+	}
+}
+
+func TestDecodeHistoric(t *testing.T) {
+	tests := []struct {
+		body    string
+		records int
+		wantErr string
+	}{
+		{body: `{"recordsCount":0,"records":null}`},
+		{body: `{"records":[{"domainName":"a.test"}],"recordsCount":1}`, records: 1},
+		{body: `{"code":123,"messages":"test error"}`},
+		{body: `[]`, wantErr: "cannot parse response: unexpected [ instead of {"},
+		{body: `{"records":{}}`, wantErr: "cannot parse response: unexpected { instead of records array"},
+		{body: `{"records":[1]}`, wantErr: "cannot parse response: json: cannot unmarshal number into Go value of type whoishistory.WhoisRecord"},
+	}
+	for _, tt := range tests {
+		response, err := decodeHistoric(strings.NewReader(tt.body), recordDecoder{}, nil)
+		checkErr(t, err, tt.wantErr)
+		if err == nil && len(response.Records) != tt.records {
+			t.Errorf("decodeHistoric(%s) records = %v", tt.body, response.Records)
+		}
+	}
+}
+
+func TestAPI_PurchaseEachBudget(t *testing.T) {
+	const resp = `{"recordsCount":2,"records":[{"domainName":"a.test"},{"domainName":"b.test"}]}`
+
+	server := whoisServer(resp, "")
+	defer server.Close()
+
+	apiURL, err := url.Parse(server.URL + pathWhoisResponseOK)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	budget := NewBudget(BudgetParams{MaxRecords: 10})
+	api := NewClient(apiKey, ClientParams{
+		HTTPClient:      server.Client(),
+		HistoricBaseURL: apiURL,
+		Budget:          budget,
+	})
+
+	n, _, err := api.PurchaseEach(context.Background(), "test.test", func(*WhoisRecord) error { return nil })
+	if err != nil {
+		t.Fatal(err)
+	}
+	if total := budget.Total(); n != 2 || total.Purchases != 1 || total.Records != 2 {
+		t.Errorf("PurchaseEach() = %d, total %+v", n, total)
+	}
+}
+
+func TestPurchaseEachWithoutStreamer(t *testing.T) {
+	// the embedded interface hides PurchaseEach of the stub
+	service := struct{ HistoricService }{&stubHistoricService{}}
+	if _, ok := interface{}(service).(HistoricStreamer); ok {
+		t.Fatal("service implements HistoricStreamer")
+	}
+
+	budget := NewBudget(BudgetParams{})
+	streamer := &budgetService{HistoricService: service, budget: budget}
+
+	var names []string
+	n, _, err := streamer.PurchaseEach(context.Background(), "a.test", func(rec *WhoisRecord) error {
+		names = append(names, rec.DomainName)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 6 || len(names) != 6 || budget.Total().Records != 6 {
+		t.Errorf("PurchaseEach() = %d, records %v, total %+v", n, names, budget.Total())
+	}
+}
+
+func TestAPI_LenientTimes(t *testing.T) {
+	const resp = `{"recordsCount":2,"records":[{"domainName":"a.test","expiresDateISO8601":"never"},{"domainName":"b.test"}]}`
+
+	server := whoisServer(resp, "")
+	defer server.Close()
+
+	api := newAPI(server, pathWhoisResponseOK)
+	_, _, err := api.Purchase(context.Background(), "test.test")
+	checkErr(t, err, `cannot parse response: cannot parse time "never"`)
+
+	apiURL, err := url.Parse(server.URL + pathWhoisResponseOK)
+	if err != nil {
+		t.Fatal(err)
+	}
+	api = NewClient(apiKey, ClientParams{
+		HTTPClient:      server.Client(),
+		HistoricBaseURL: apiURL,
+		LenientTimes:    true,
+	})
+
+	records, _, err := api.Purchase(context.Background(), "test.test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 2 || len(records[0].InvalidTimes) != 1 || records[0].InvalidTimes[0].Value != "never" {
+		t.Errorf("records = %+v", records)
+	}
+}
@@ -0,0 +1,299 @@
+package whoishistory
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// JobStatus is a state of a domain in Job.
+type JobStatus string
+
+// Domain states in Job.
+const (
+	// JobStatusPending means that the domain hasn't been processed yet.
+	JobStatusPending JobStatus = "pending"
+	// JobStatusDone means that the records of the domain are saved.
+	// A domain with saved records is done even if its state wasn't written.
+	JobStatusDone JobStatus = "done"
+	// JobStatusFailed means that the last attempt failed.
+	JobStatusFailed JobStatus = "failed"
+)
+
+// JobParams is used to create Job.
+type JobParams struct {
+	// Dir is the directory for the job state. It's created if missing.
+	Dir string
+	// Workers is the number of domains processed concurrently.
+	Workers int
+	// Options are applied to every Purchase call.
+	Options []Option
+	// MaxAttempts limits the number of attempts per domain across restarts.
+	// Zero means that failed domains are retried on every run.
+	MaxAttempts int
+}
+
+// JobOutcome is the persisted state of a domain.
+type JobOutcome struct {
+	Domain    string    `json:"domain"`
+	Status    JobStatus `json:"status"`
+	Records   int       `json:"records"`
+	Attempts  int       `json:"attempts"`
+	Error     string    `json:"error,omitempty"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// JobManifest summarizes outcomes of all domains of the job.
+type JobManifest struct {
+	Total   int `json:"total"`
+	Done    int `json:"done"`
+	Failed  int `json:"failed"`
+	Pending int `json:"pending"`
+	// Skipped is the number of domains done by previous runs.
+	Skipped    int          `json:"skipped"`
+	Domains    []JobOutcome `json:"domains"`
+	FinishedAt time.Time    `json:"finishedAt"`
+}
+
+// Job purchases history of domains saving the records and per-domain state
+// to a directory as it goes. A restarted job skips domains already done
+// and retries failed ones. Domains are identified by names normalized
+// with NormalizeDomain, so "A.test" and "a.test" are purchased once.
+type Job struct {
+	service HistoricService
+	params  JobParams
+}
+
+const (
+	jobStateDir     = "state"
+	jobRecordsDir   = "records"
+	jobManifestFile = "manifest.json"
+)
+
+// NewJob creates Job on top of the service.
+func NewJob(service HistoricService, params JobParams) (*Job, error) {
+	if params.Dir == "" {
+		return nil, &ArgError{"Dir", "cannot be empty"}
+	}
+
+	for _, dir := range []string{jobStateDir, jobRecordsDir} {
+		if err := os.MkdirAll(filepath.Join(params.Dir, dir), 0755); err != nil {
+			return nil, fmt.Errorf("cannot create job directory: %w", err)
+		}
+	}
+
+	return &Job{service: service, params: params}, nil
+}
+
+// Run processes the domains and writes the manifest.
+// If the context is done then unprocessed domains are reported as pending
+// and the context error is returned along with the manifest.
+func (j *Job) Run(ctx context.Context, domains []string) (*JobManifest, error) {
+
+	outcomes := make(map[string]*JobOutcome, len(domains))
+	var names, queue []string
+
+	manifest := &JobManifest{}
+
+	for _, name := range domains {
+		name = jobKey(name)
+		if _, ok := outcomes[name]; ok {
+			continue
+		}
+		names = append(names, name)
+
+		outcome, err := j.loadOutcome(name)
+		if err != nil {
+			return nil, err
+		}
+		outcomes[name] = outcome
+
+		switch {
+		case outcome.Status == JobStatusDone:
+			manifest.Skipped++
+		case outcome.Status == JobStatusFailed && j.params.MaxAttempts > 0 &&
+			outcome.Attempts >= j.params.MaxAttempts:
+		default:
+			queue = append(queue, name)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	bulk := NewBulk(j.service, BulkParams{
+		Workers:  j.params.Workers,
+		Purchase: true,
+		Options:  j.params.Options,
+	})
+
+	var saveErr error
+	for res := range bulk.RunSlice(ctx, queue).Results() {
+		if saveErr != nil {
+			continue
+		}
+		if res.Err != nil && (errors.Is(res.Err, context.Canceled) || errors.Is(res.Err, context.DeadlineExceeded)) {
+			continue
+		}
+
+		outcome := outcomes[res.Domain]
+		outcome.Attempts++
+		outcome.UpdatedAt = time.Now().UTC()
+		if res.Err != nil {
+			outcome.Status = JobStatusFailed
+			outcome.Error = res.Err.Error()
+		} else {
+			outcome.Status = JobStatusDone
+			outcome.Error = ""
+			outcome.Records = len(res.Records)
+			saveErr = j.saveRecords(res.Domain, res.Records)
+		}
+
+		if saveErr == nil {
+			saveErr = j.saveOutcome(outcome)
+		}
+		if saveErr != nil {
+			cancel()
+		}
+	}
+	if saveErr != nil {
+		return nil, saveErr
+	}
+
+	for _, name := range names {
+		outcome := outcomes[name]
+		switch outcome.Status {
+		case JobStatusDone:
+			manifest.Done++
+		case JobStatusFailed:
+			manifest.Failed++
+		default:
+			manifest.Pending++
+		}
+		manifest.Domains = append(manifest.Domains, *outcome)
+	}
+	manifest.Total = len(names)
+	manifest.FinishedAt = time.Now().UTC()
+
+	if err := writeJSONFile(filepath.Join(j.params.Dir, jobManifestFile), manifest); err != nil {
+		return nil, err
+	}
+
+	return manifest, ctx.Err()
+}
+
+// Records loads the saved records of the domain.
+func (j *Job) Records(name string) ([]*WhoisRecord, error) {
+	var records []*WhoisRecord
+	err := readJSONFile(j.path(jobRecordsDir, jobKey(name)), &records)
+	if err != nil {
+		return nil, err
+	}
+	return records, nil
+}
+
+// jobKey returns the name identifying the domain in the job.
+// Invalid names are kept lowercased so their purchase fails with ArgError.
+func jobKey(name string) string {
+	if domain, err := NormalizeDomain(name); err == nil {
+		return domain
+	}
+	return strings.ToLower(strings.TrimSpace(name))
+}
+
+func (j *Job) path(dir, name string) string {
+	return filepath.Join(j.params.Dir, dir, url.PathEscape(name)+".json")
+}
+
+// loadOutcome reads the state of the domain. Records are saved before the state,
+// so saved records mean that the domain is done even without the state.
+func (j *Job) loadOutcome(name string) (*JobOutcome, error) {
+	outcome := &JobOutcome{Domain: name, Status: JobStatusPending}
+	err := readJSONFile(j.path(jobStateDir, name), outcome)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	outcome.Domain = name
+
+	if outcome.Status != JobStatusDone {
+		records, err := j.Records(name)
+		switch {
+		case err == nil:
+			outcome.Status = JobStatusDone
+			outcome.Records = len(records)
+			outcome.Error = ""
+		case !errors.Is(err, os.ErrNotExist):
+			return nil, err
+		}
+	}
+	return outcome, nil
+}
+
+func (j *Job) saveOutcome(outcome *JobOutcome) error {
+	return writeJSONFile(j.path(jobStateDir, outcome.Domain), outcome)
+}
+
+func (j *Job) saveRecords(name string, records []*WhoisRecord) error {
+	return writeJSONFile(j.path(jobRecordsDir, name), records)
+}
+
+func readJSONFile(path string, v interface{}) error {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("cannot read file: %w", err)
+	}
+	if err := json.Unmarshal(b, v); err != nil {
+		return fmt.Errorf("cannot parse file %s: %w", path, err)
+	}
+	return nil
+}
+
+// writeJSONFile replaces the file atomically so an interrupted write
+// never leaves a truncated file behind. The file is synced to disk before
+// it's renamed, so a file once in place survives a crash.
+func writeJSONFile(path string, v interface{}) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("cannot encode file %s: %w", path, err)
+	}
+
+	f, err := ioutil.TempFile(filepath.Dir(path), ".tmp-*")
+	if err != nil {
+		return fmt.Errorf("cannot write file: %w", err)
+	}
+
+	_, err = f.Write(b)
+	if err == nil {
+		err = f.Sync()
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(f.Name(), path)
+	}
+	if err != nil {
+		_ = os.Remove(f.Name())
+		return fmt.Errorf("cannot write file: %w", err)
+	}
+	syncDir(filepath.Dir(path))
+	return nil
+}
+
+// syncDir persists the rename in the directory. Errors are ignored
+// since some systems cannot sync directories.
+func syncDir(dir string) {
+	d, err := os.Open(dir)
+	if err != nil {
+		return
+	}
+	_ = d.Sync()
+	_ = d.Close()
+}
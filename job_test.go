@@ -0,0 +1,189 @@
+package whoishistory
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestJob(t *testing.T) {
+	dir, cleanup := tempDir(t)
+	defer cleanup()
+
+	service := &stubHistoricService{failed: map[string]bool{"b.test": true, "c.test": true}}
+
+	job, err := NewJob(service, JobParams{Dir: dir, Workers: 2, MaxAttempts: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	domains := []string{"a.test", "b.test", "c.test", "a.test", "dd.test"}
+
+	manifest, err := job.Run(context.Background(), domains)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if manifest.Total != 4 || manifest.Done != 2 || manifest.Failed != 2 || manifest.Skipped != 0 {
+		t.Errorf("first run manifest = %+v", manifest)
+	}
+	if n := atomic.LoadInt32(&service.purchases); n != 4 {
+		t.Errorf("first run purchases = %d, want 4", n)
+	}
+
+	// The restarted job skips done domains and retries failed ones.
+	service.failed = map[string]bool{"c.test": true}
+	atomic.StoreInt32(&service.purchases, 0)
+
+	job, err = NewJob(service, JobParams{Dir: dir, Workers: 2, MaxAttempts: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	manifest, err = job.Run(context.Background(), domains)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if manifest.Total != 4 || manifest.Done != 3 || manifest.Failed != 1 || manifest.Skipped != 2 {
+		t.Errorf("second run manifest = %+v", manifest)
+	}
+	if n := atomic.LoadInt32(&service.purchases); n != 2 {
+		t.Errorf("second run purchases = %d, want 2", n)
+	}
+
+	// c.test has no attempts left.
+	atomic.StoreInt32(&service.purchases, 0)
+	manifest, err = job.Run(context.Background(), domains)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := atomic.LoadInt32(&service.purchases); n != 0 {
+		t.Errorf("third run purchases = %d, want 0", n)
+	}
+
+	for _, o := range manifest.Domains {
+		if o.Domain == "c.test" && (o.Status != JobStatusFailed || o.Attempts != 2 || o.Error != errStub.Error()) {
+			t.Errorf("c.test outcome = %+v", o)
+		}
+	}
+
+	records, err := job.Records("dd.test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 7 || records[0].DomainName != "dd.test" {
+		t.Errorf("Records() = %v", records)
+	}
+
+	var saved JobManifest
+	if err := readJSONFile(filepath.Join(dir, jobManifestFile), &saved); err != nil {
+		t.Fatal(err)
+	}
+	if saved.Total != 4 || len(saved.Domains) != 4 {
+		t.Errorf("saved manifest = %+v", saved)
+	}
+}
+
+func TestJobCanceled(t *testing.T) {
+	dir, cleanup := tempDir(t)
+	defer cleanup()
+
+	job, err := NewJob(&stubHistoricService{}, JobParams{Dir: dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	manifest, err := job.Run(ctx, []string{"a.test", "b.test"})
+	checkErr(t, err, "context canceled")
+	if manifest.Pending != 2 {
+		t.Errorf("manifest = %+v", manifest)
+	}
+}
+
+func TestJobCanceledSavesPurchased(t *testing.T) {
+	dir, cleanup := tempDir(t)
+	defer cleanup()
+
+	service := &stubHistoricService{delay: 20 * time.Millisecond, uncancelable: true}
+	job, err := NewJob(service, JobParams{Dir: dir, Workers: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Millisecond)
+	defer cancel()
+
+	manifest, err := job.Run(ctx, []string{"a.test", "b.test", "c.test", "d.test"})
+	checkErr(t, err, "context deadline exceeded")
+
+	purchased := int(atomic.LoadInt32(&service.purchases))
+	if purchased == 0 || manifest.Done != purchased || manifest.Pending != 4-purchased {
+		t.Errorf("purchases = %d, manifest = %+v", purchased, manifest)
+	}
+}
+
+func TestJobNormalizesDomains(t *testing.T) {
+	dir, cleanup := tempDir(t)
+	defer cleanup()
+
+	service := &stubHistoricService{}
+	job, err := NewJob(service, JobParams{Dir: dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	manifest, err := job.Run(context.Background(), []string{"A.test", "a.test", " a.test. "})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := atomic.LoadInt32(&service.purchases); n != 1 {
+		t.Errorf("purchases = %d, want 1", n)
+	}
+	if manifest.Total != 1 || manifest.Domains[0].Domain != "a.test" {
+		t.Errorf("manifest = %+v", manifest)
+	}
+	if _, err := job.Records("A.TEST"); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestJobRecordsWithoutState(t *testing.T) {
+	dir, cleanup := tempDir(t)
+	defer cleanup()
+
+	service := &stubHistoricService{}
+	job, err := NewJob(service, JobParams{Dir: dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the job was killed after saving the records but before saving the state
+	if err := job.saveRecords("a.test", []*WhoisRecord{{DomainName: "a.test"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	manifest, err := job.Run(context.Background(), []string{"a.test"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := atomic.LoadInt32(&service.purchases); n != 0 {
+		t.Errorf("purchases = %d, want 0", n)
+	}
+	if manifest.Skipped != 1 || manifest.Done != 1 || manifest.Domains[0].Records != 1 {
+		t.Errorf("manifest = %+v", manifest)
+	}
+}
+
+func tempDir(t *testing.T) (string, func()) {
+	dir, err := ioutil.TempDir("", "whoishistory")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return dir, func() { _ = os.RemoveAll(dir) }
+}
@@ -0,0 +1,73 @@
+package whoishistory
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// ErrResponseTooLarge is matched by ResponseSizeError with errors.Is.
+var ErrResponseTooLarge = errors.New("response too large")
+
+// ResponseSizeError is returned when the response body exceeds ClientParams.MaxResponseSize.
+type ResponseSizeError struct {
+	// Limit is the maximum size of the response body.
+	Limit int64
+	// Size is the Content-Length of the response if it's known. It's -1 otherwise.
+	Size int64
+}
+
+func (e *ResponseSizeError) Error() string {
+	if e.Size < 0 {
+		return "response too large: body exceeds limit of " + strconv.FormatInt(e.Limit, 10) + " bytes"
+	}
+	return "response too large: body has " + strconv.FormatInt(e.Size, 10) +
+		" bytes, limit is " + strconv.FormatInt(e.Limit, 10) + " bytes"
+}
+
+// Is reports whether the target is ErrResponseTooLarge.
+func (e *ResponseSizeError) Is(target error) bool {
+	return target == ErrResponseTooLarge
+}
+
+// TextLimits reduce the memory taken by text fields of decoded records:
+// WhoisRecord.RawText, WhoisRecord.CleanText and Contact.RawText.
+type TextLimits struct {
+	// Drop empties the text fields.
+	Drop bool
+	// MaxLength truncates the text fields to the number of bytes.
+	// Zero means no limit.
+	MaxLength int
+}
+
+// apply trims text fields of the record. It does nothing if limits are nil.
+func (l *TextLimits) apply(rec *WhoisRecord) {
+	if l == nil || rec == nil || !l.Drop && l.MaxLength <= 0 {
+		return
+	}
+
+	rec.RawText = l.trim(rec.RawText)
+	rec.CleanText = l.trim(rec.CleanText)
+	for _, c := range []*Contact{
+		&rec.RegistrantContact,
+		&rec.AdministrativeContact,
+		&rec.TechnicalContact,
+		&rec.BillingContact,
+		&rec.ZoneContact,
+	} {
+		c.RawText = l.trim(c.RawText)
+	}
+}
+
+func (l *TextLimits) trim(s string) string {
+	if l.Drop {
+		return ""
+	}
+	if len(s) <= l.MaxLength {
+		return s
+	}
+	// copy the prefix to release the original string
+	var b strings.Builder
+	b.WriteString(strings.ToValidUTF8(s[:l.MaxLength], ""))
+	return b.String()
+}
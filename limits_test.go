@@ -0,0 +1,124 @@
+package whoishistory
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestAPI_MaxResponseSize(t *testing.T) {
+	const resp = `{"recordsCount":2,"records":[{"domainName":"a.test"},{"domainName":"b.test"}]}`
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.URL.Query().Get("domainName") == "chunked.test" {
+			// flushing before writing the body hides the content length
+			w.(http.Flusher).Flush()
+		}
+		_, _ = w.Write([]byte(resp))
+	}))
+	defer server.Close()
+
+	apiURL, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range []struct {
+		domain  string
+		limit   int64
+		wantErr string
+	}{
+		{domain: "test.test", limit: int64(len(resp))},
+		{domain: "chunked.test", limit: int64(len(resp))},
+		{domain: "test.test", limit: 20, wantErr: "response too large: body has 78 bytes, limit is 20 bytes"},
+		{domain: "chunked.test", limit: 20, wantErr: "response too large: body exceeds limit of 20 bytes"},
+	} {
+		api := NewClient(apiKey, ClientParams{
+			HTTPClient:      server.Client(),
+			HistoricBaseURL: apiURL,
+			MaxResponseSize: tt.limit,
+		})
+
+		records, response, err := api.Purchase(context.Background(), tt.domain)
+		checkErr(t, err, tt.wantErr)
+		if tt.wantErr == "" {
+			if len(records) != 2 {
+				t.Errorf("%s: records = %v", tt.domain, records)
+			}
+			continue
+		}
+		if !errors.Is(err, ErrResponseTooLarge) || ErrorKind(err) != "too_large" {
+			t.Errorf("%s: error %v is not ErrResponseTooLarge", tt.domain, err)
+		}
+		if response == nil || response.StatusCode != http.StatusOK || response.BytesRead > tt.limit {
+			t.Errorf("%s: response = %+v", tt.domain, response)
+		}
+	}
+}
+
+func TestTextLimits(t *testing.T) {
+	newRecord := func() *WhoisRecord {
+		return &WhoisRecord{
+			RawText:           "raw text",
+			CleanText:         "clean text",
+			RegistrantContact: Contact{Name: "name", RawText: "contact ëë"},
+			ZoneContact:       Contact{RawText: "zone"},
+		}
+	}
+
+	rec := newRecord()
+	(&TextLimits{MaxLength: 9}).apply(rec)
+	if rec.RawText != "raw text" || rec.CleanText != "clean tex" ||
+		rec.RegistrantContact.RawText != "contact " || rec.ZoneContact.RawText != "zone" {
+		t.Errorf("truncated record = %+v", rec)
+	}
+
+	rec = newRecord()
+	(&TextLimits{Drop: true}).apply(rec)
+	if rec.RawText != "" || rec.CleanText != "" || rec.RegistrantContact.RawText != "" ||
+		rec.ZoneContact.RawText != "" || rec.RegistrantContact.Name != "name" {
+		t.Errorf("dropped record = %+v", rec)
+	}
+
+	rec = newRecord()
+	var limits *TextLimits
+	limits.apply(rec)
+	if rec.CleanText != "clean text" {
+		t.Errorf("nil limits changed record = %+v", rec)
+	}
+}
+
+func TestAPI_TextLimits(t *testing.T) {
+	const resp = `{"recordsCount":1,"records":[{"domainName":"a.test","rawText":"` +
+		`long raw text","registrantContact":{"rawText":"long contact text"}}]}`
+
+	server := whoisServer(resp, "")
+	defer server.Close()
+
+	apiURL, err := url.Parse(server.URL + pathWhoisResponseOK)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	api := NewClient(apiKey, ClientParams{
+		HTTPClient:      server.Client(),
+		HistoricBaseURL: apiURL,
+		TextLimits:      &TextLimits{MaxLength: 4},
+	})
+
+	var texts []string
+	_, _, err = api.PurchaseEach(context.Background(), "test.test", func(rec *WhoisRecord) error {
+		texts = append(texts, rec.RawText, rec.RegistrantContact.RawText)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(texts, ","); got != "long,long" {
+		t.Errorf("texts = %v", got)
+	}
+}
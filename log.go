@@ -0,0 +1,107 @@
+package whoishistory
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"regexp"
+	"time"
+)
+
+// Logger is a structured logger accepting alternating key/value pairs.
+// *slog.Logger from log/slog implements it.
+type Logger interface {
+	Info(msg string, keyvals ...interface{})
+	Error(msg string, keyvals ...interface{})
+}
+
+const redacted = "REDACTED"
+
+var apiKeyPattern = regexp.MustCompile(`(apiKey=)[^&\s"']*`)
+
+// RedactURL returns the URL string with the API key replaced.
+func RedactURL(u *url.URL) string {
+	if u == nil {
+		return ""
+	}
+	c := *u
+	q := c.Query()
+	if q.Get("apiKey") != "" {
+		q.Set("apiKey", redacted)
+		c.RawQuery = q.Encode()
+	}
+	return c.String()
+}
+
+// redactString replaces API keys passed as URL parameters in the string.
+func redactString(s string) string {
+	return apiKeyPattern.ReplaceAllString(s, "${1}"+redacted)
+}
+
+// redactError removes the API key from URL errors returned by http.Client.
+func redactError(err error) error {
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		urlErr.URL = redactString(urlErr.URL)
+	}
+	return err
+}
+
+// DumpRequest returns the wire representation of the request with the API key redacted.
+func DumpRequest(req *http.Request, body bool) ([]byte, error) {
+	clone := req.Clone(req.Context())
+	u, err := url.Parse(RedactURL(req.URL))
+	if err != nil {
+		return nil, err
+	}
+	clone.URL = u
+
+	b, err := httputil.DumpRequestOut(clone, body)
+	if err != nil {
+		return nil, redactError(err)
+	}
+	return b, nil
+}
+
+// optionsString encodes options applied to empty query values.
+func optionsString(opts []Option) string {
+	v := url.Values{}
+	for _, opt := range opts {
+		opt(v)
+	}
+	return redactString(v.Encode())
+}
+
+func logRequestStart(logger Logger, outcome *Outcome, opts []Option) {
+	logger.Info("whois history request started",
+		"domain", outcome.Domain,
+		"mode", outcome.Mode,
+		"options", optionsString(opts),
+	)
+}
+
+func logRequestFinish(logger Logger, outcome *Outcome, opts []Option, duration time.Duration) {
+	keyvals := []interface{}{
+		"domain", outcome.Domain,
+		"mode", outcome.Mode,
+		"options", optionsString(opts),
+		"duration", duration,
+	}
+	if resp := outcome.Response; resp != nil && resp.Response != nil {
+		keyvals = append(keyvals,
+			"status", resp.StatusCode,
+			"attempts", resp.Attempts,
+			"cache_hit", resp.CacheHit,
+		)
+	}
+
+	if outcome.Err != nil {
+		keyvals = append(keyvals, "error", redactString(outcome.Err.Error()))
+		logger.Error("whois history request failed", keyvals...)
+		return
+	}
+
+	keyvals = append(keyvals, "records", outcome.RecordsCount)
+	logger.Info("whois history request finished", keyvals...)
+}
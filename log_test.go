@@ -0,0 +1,153 @@
+package whoishistory
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+type logEntry struct {
+	level   string
+	msg     string
+	keyvals map[string]interface{}
+}
+
+type testLogger struct {
+	mu      sync.Mutex
+	entries []logEntry
+}
+
+func (l *testLogger) log(level, msg string, keyvals []interface{}) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	e := logEntry{level: level, msg: msg, keyvals: map[string]interface{}{}}
+	for i := 0; i+1 < len(keyvals); i += 2 {
+		e.keyvals[keyvals[i].(string)] = keyvals[i+1]
+	}
+	l.entries = append(l.entries, e)
+}
+
+func (l *testLogger) Info(msg string, keyvals ...interface{}) {
+	l.log("info", msg, keyvals)
+}
+
+func (l *testLogger) Error(msg string, keyvals ...interface{}) {
+	l.log("error", msg, keyvals)
+}
+
+func TestLogger(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		_, _ = w.Write([]byte(`{"recordsCount":2,"records":[{"domainName":"a.test"},{"domainName":"b.test"}]}`))
+	}))
+	defer server.Close()
+
+	apiURL, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	logger := &testLogger{}
+	api := NewClient(apiKey, ClientParams{
+		HTTPClient:      server.Client(),
+		HistoricBaseURL: apiURL,
+		Logger:          logger,
+	})
+
+	since := OptionSinceDate(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC))
+	if _, _, err := api.Purchase(context.Background(), "test.test", since); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(logger.entries) != 2 {
+		t.Fatalf("entries = %+v", logger.entries)
+	}
+
+	start, finish := logger.entries[0], logger.entries[1]
+	if start.msg != "whois history request started" || start.keyvals["domain"] != "test.test" ||
+		start.keyvals["mode"] != modePurchase || start.keyvals["options"] != "sinceDate=2020-01-01" {
+		t.Errorf("start = %+v", start)
+	}
+	if finish.level != "info" || finish.keyvals["status"] != 200 || finish.keyvals["records"] != 2 ||
+		finish.keyvals["attempts"] != 1 {
+		t.Errorf("finish = %+v", finish)
+	}
+	if _, ok := finish.keyvals["duration"].(time.Duration); !ok {
+		t.Errorf("duration = %v", finish.keyvals["duration"])
+	}
+}
+
+func TestLoggerRedactsErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {}))
+	apiURL, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	server.Close()
+
+	logger := &testLogger{}
+	api := NewClient(apiKey, ClientParams{
+		HistoricBaseURL: apiURL,
+		Logger:          logger,
+	})
+
+	_, _, err = api.Preview(context.Background(), "test.test")
+	if err == nil {
+		t.Fatal("error expected")
+	}
+	if strings.Contains(err.Error(), apiKey) {
+		t.Errorf("error contains API key: %v", err)
+	}
+	if !strings.Contains(err.Error(), "apiKey="+redacted) {
+		t.Errorf("error is not redacted: %v", err)
+	}
+
+	for _, e := range logger.entries {
+		if s := fmt.Sprint(e.keyvals); strings.Contains(s, apiKey) {
+			t.Errorf("log entry contains API key: %s", s)
+		}
+	}
+	if last := logger.entries[len(logger.entries)-1]; last.level != "error" {
+		t.Errorf("last entry = %+v", last)
+	}
+}
+
+func TestRedact(t *testing.T) {
+	u, err := url.Parse("https://example.com/api?apiKey=" + apiKey + "&domainName=test.test")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got, want := RedactURL(u), "https://example.com/api?apiKey=REDACTED&domainName=test.test"; got != want {
+		t.Errorf("RedactURL() = %v, want %v", got, want)
+	}
+	if u.Query().Get("apiKey") != apiKey {
+		t.Error("RedactURL() modified the URL")
+	}
+
+	s := `Get "https://example.com/api?apiKey=` + apiKey + `&mode=preview": EOF`
+	if got, want := redactString(s), `Get "https://example.com/api?apiKey=REDACTED&mode=preview": EOF`; got != want {
+		t.Errorf("redactString() = %v, want %v", got, want)
+	}
+
+	req, err := http.NewRequest(http.MethodGet, u.String(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dump, err := DumpRequest(req, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(dump), apiKey) || !strings.Contains(string(dump), "apiKey=REDACTED") {
+		t.Errorf("DumpRequest() = %s", dump)
+	}
+	if req.URL.Query().Get("apiKey") != apiKey {
+		t.Error("DumpRequest() modified the request")
+	}
+}
@@ -0,0 +1,252 @@
+package whoishistory
+
+import (
+	"expvar"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RequestMetrics describes one HistoricService call.
+type RequestMetrics struct {
+	// Mode is either "preview" or "purchase".
+	Mode string
+	// StatusClass is the class of the HTTP status code like "2xx".
+	// It's "none" if no response was received.
+	StatusClass string
+	// ErrorKind is the result of ErrorKind for the returned error.
+	ErrorKind string
+	Duration  time.Duration
+	Records   int
+	// BytesRead is the size of the response body read from the network.
+	BytesRead int64
+}
+
+// Metrics receives measurements of every HistoricService call.
+// Implementations must be safe for concurrent use.
+type Metrics interface {
+	ObserveRequest(m RequestMetrics)
+}
+
+func statusClass(resp *Response) string {
+	if resp == nil || resp.Response == nil || resp.StatusCode == 0 {
+		return "none"
+	}
+	return strconv.Itoa(resp.StatusCode/100) + "xx"
+}
+
+func requestMetrics(outcome *Outcome, duration time.Duration) RequestMetrics {
+	m := RequestMetrics{
+		Mode:        outcome.Mode,
+		StatusClass: statusClass(outcome.Response),
+		ErrorKind:   ErrorKind(outcome.Err),
+		Duration:    duration,
+	}
+	if outcome.Err == nil {
+		m.Records = outcome.RecordsCount
+	}
+	if outcome.Response != nil {
+		m.BytesRead = outcome.Response.BytesRead
+	}
+	return m
+}
+
+// Default histogram buckets of MetricsRegistry.
+var (
+	DefaultDurationBuckets = []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30}
+	DefaultRecordsBuckets  = []float64{0, 1, 5, 10, 50, 100, 500, 1000, 5000}
+)
+
+const metricsPrefix = "whoishistory_"
+
+// MetricsRegistry is Metrics keeping counters and histograms in memory.
+// It exposes them with expvar and in the Prometheus text format.
+type MetricsRegistry struct {
+	mu sync.Mutex
+
+	requests map[string]float64
+	bytes    map[string]float64
+	duration map[string]*histogram
+	records  map[string]*histogram
+
+	durationBuckets []float64
+	recordsBuckets  []float64
+}
+
+var _ Metrics = &MetricsRegistry{}
+var _ http.Handler = &MetricsRegistry{}
+
+// NewMetricsRegistry creates MetricsRegistry with default buckets.
+func NewMetricsRegistry() *MetricsRegistry {
+	return &MetricsRegistry{
+		requests:        make(map[string]float64),
+		bytes:           make(map[string]float64),
+		duration:        make(map[string]*histogram),
+		records:         make(map[string]*histogram),
+		durationBuckets: DefaultDurationBuckets,
+		recordsBuckets:  DefaultRecordsBuckets,
+	}
+}
+
+type histogram struct {
+	buckets []float64
+	counts  []uint64
+	sum     float64
+	count   uint64
+}
+
+func (h *histogram) observe(v float64) {
+	for i, b := range h.buckets {
+		if v <= b {
+			h.counts[i]++
+		}
+	}
+	h.sum += v
+	h.count++
+}
+
+// labels encodes label pairs in the Prometheus format.
+func labels(pairs ...string) string {
+	var b strings.Builder
+	for i := 0; i+1 < len(pairs); i += 2 {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(pairs[i])
+		b.WriteString(`="`)
+		b.WriteString(strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(pairs[i+1]))
+		b.WriteByte('"')
+	}
+	return b.String()
+}
+
+// ObserveRequest updates counters and histograms.
+func (r *MetricsRegistry) ObserveRequest(m RequestMetrics) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	mode := labels("mode", m.Mode)
+
+	r.requests[labels("mode", m.Mode, "status_class", m.StatusClass, "error_kind", m.ErrorKind)]++
+	r.bytes[mode] += float64(m.BytesRead)
+
+	r.histogram(r.duration, mode, r.durationBuckets).observe(m.Duration.Seconds())
+	if m.ErrorKind == "" {
+		r.histogram(r.records, mode, r.recordsBuckets).observe(float64(m.Records))
+	}
+}
+
+func (r *MetricsRegistry) histogram(m map[string]*histogram, key string, buckets []float64) *histogram {
+	h, ok := m[key]
+	if !ok {
+		h = &histogram{buckets: buckets, counts: make([]uint64, len(buckets))}
+		m[key] = h
+	}
+	return h
+}
+
+// WriteTo writes metrics in the Prometheus text exposition format.
+func (r *MetricsRegistry) WriteTo(w io.Writer) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var b strings.Builder
+
+	writeCounter(&b, "requests_total", "Number of Whois History API calls.", r.requests)
+	writeCounter(&b, "response_bytes_total", "Number of response body bytes read.", r.bytes)
+	writeHistogram(&b, "request_duration_seconds", "Duration of Whois History API calls.", r.duration)
+	writeHistogram(&b, "records_returned", "Number of records returned by successful calls.", r.records)
+
+	n, err := io.WriteString(w, b.String())
+	return int64(n), err
+}
+
+// ServeHTTP serves metrics in the Prometheus text exposition format.
+func (r *MetricsRegistry) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	_, _ = r.WriteTo(w)
+}
+
+// Publish exports metrics with expvar under the name.
+// Like expvar.Publish it panics if the name is already registered.
+func (r *MetricsRegistry) Publish(name string) {
+	expvar.Publish(name, expvar.Func(r.expvar))
+}
+
+func (r *MetricsRegistry) expvar() interface{} {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	hist := func(m map[string]*histogram) map[string]interface{} {
+		out := make(map[string]interface{}, len(m))
+		for k, h := range m {
+			buckets := make(map[string]uint64, len(h.buckets))
+			for i, b := range h.buckets {
+				buckets[formatFloat(b)] = h.counts[i]
+			}
+			out[k] = map[string]interface{}{"buckets": buckets, "sum": h.sum, "count": h.count}
+		}
+		return out
+	}
+	copyCounter := func(m map[string]float64) map[string]float64 {
+		out := make(map[string]float64, len(m))
+		for k, v := range m {
+			out[k] = v
+		}
+		return out
+	}
+
+	return map[string]interface{}{
+		"requests_total":           copyCounter(r.requests),
+		"response_bytes_total":     copyCounter(r.bytes),
+		"request_duration_seconds": hist(r.duration),
+		"records_returned":         hist(r.records),
+	}
+}
+
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+func sortedKeys(m interface{}) []string {
+	var keys []string
+	switch m := m.(type) {
+	case map[string]float64:
+		for k := range m {
+			keys = append(keys, k)
+		}
+	case map[string]*histogram:
+		for k := range m {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func writeCounter(b *strings.Builder, name, help string, m map[string]float64) {
+	name = metricsPrefix + name
+	fmt.Fprintf(b, "# HELP %s %s\n# TYPE %s counter\n", name, help, name)
+	for _, k := range sortedKeys(m) {
+		fmt.Fprintf(b, "%s{%s} %s\n", name, k, formatFloat(m[k]))
+	}
+}
+
+func writeHistogram(b *strings.Builder, name, help string, m map[string]*histogram) {
+	name = metricsPrefix + name
+	fmt.Fprintf(b, "# HELP %s %s\n# TYPE %s histogram\n", name, help, name)
+	for _, k := range sortedKeys(m) {
+		h := m[k]
+		for i, bucket := range h.buckets {
+			fmt.Fprintf(b, "%s_bucket{%s,le=\"%s\"} %d\n", name, k, formatFloat(bucket), h.counts[i])
+		}
+		fmt.Fprintf(b, "%s_bucket{%s,le=\"+Inf\"} %d\n", name, k, h.count)
+		fmt.Fprintf(b, "%s_sum{%s} %s\n", name, k, formatFloat(h.sum))
+		fmt.Fprintf(b, "%s_count{%s} %d\n", name, k, h.count)
+	}
+}
@@ -0,0 +1,152 @@
+package whoishistory
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"expvar"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// expvarRuns makes expvar names unique since they cannot be published twice
+// when tests run repeatedly in the same process.
+var expvarRuns int32
+
+func TestMetricsRegistry(t *testing.T) {
+	r := NewMetricsRegistry()
+
+	r.ObserveRequest(RequestMetrics{Mode: "preview", StatusClass: "2xx", Duration: 80 * time.Millisecond, Records: 3, BytesRead: 100})
+	r.ObserveRequest(RequestMetrics{Mode: "preview", StatusClass: "2xx", Duration: 2 * time.Second, Records: 12, BytesRead: 50})
+	r.ObserveRequest(RequestMetrics{Mode: "purchase", StatusClass: "4xx", ErrorKind: "unauthorized", Duration: 40 * time.Millisecond, BytesRead: 20})
+
+	var b strings.Builder
+	if _, err := r.WriteTo(&b); err != nil {
+		t.Fatal(err)
+	}
+	out := b.String()
+
+	for _, want := range []string{
+		"# TYPE whoishistory_requests_total counter\n",
+		`whoishistory_requests_total{mode="preview",status_class="2xx",error_kind=""} 2` + "\n",
+		`whoishistory_requests_total{mode="purchase",status_class="4xx",error_kind="unauthorized"} 1` + "\n",
+		`whoishistory_response_bytes_total{mode="preview"} 150` + "\n",
+		"# TYPE whoishistory_request_duration_seconds histogram\n",
+		`whoishistory_request_duration_seconds_bucket{mode="preview",le="0.1"} 1` + "\n",
+		`whoishistory_request_duration_seconds_bucket{mode="preview",le="+Inf"} 2` + "\n",
+		`whoishistory_request_duration_seconds_count{mode="purchase"} 1` + "\n",
+		`whoishistory_records_returned_bucket{mode="preview",le="10"} 1` + "\n",
+		`whoishistory_records_returned_sum{mode="preview"} 15` + "\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output does not contain %q", want)
+		}
+	}
+	if strings.Contains(out, `whoishistory_records_returned_count{mode="purchase"}`) {
+		t.Error("records are observed for failed request")
+	}
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/plain; version=0.0.4") {
+		t.Errorf("content type = %v", ct)
+	}
+	if rec.Body.String() != out {
+		t.Error("ServeHTTP() output differs from WriteTo()")
+	}
+
+	name := "whoishistory_test_" + strconv.Itoa(int(atomic.AddInt32(&expvarRuns, 1)))
+	r.Publish(name)
+	var vars map[string]map[string]interface{}
+	if err := json.Unmarshal([]byte(expvar.Get(name).String()), &vars); err != nil {
+		t.Fatal(err)
+	}
+	if got := vars["requests_total"][`mode="preview",status_class="2xx",error_kind=""`]; got != 2.0 {
+		t.Errorf("expvar requests = %v", got)
+	}
+}
+
+type recordingMetrics struct {
+	observed []RequestMetrics
+}
+
+func (m *recordingMetrics) ObserveRequest(rm RequestMetrics) {
+	m.observed = append(m.observed, rm)
+}
+
+func TestAPI_Metrics(t *testing.T) {
+	const resp = `{"recordsCount":2,"records":[{"domainName":"a.test"},{"domainName":"b.test"}]}`
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.URL.Query().Get("domainName") == "fail.test" {
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte(`{"code":401,"messages":"invalid key"}`))
+			return
+		}
+		_, _ = w.Write([]byte(resp))
+	}))
+	defer server.Close()
+
+	apiURL, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	metrics := &recordingMetrics{}
+	api := NewClient(apiKey, ClientParams{
+		HTTPClient:      server.Client(),
+		HistoricBaseURL: apiURL,
+		Metrics:         metrics,
+	})
+
+	ctx := context.Background()
+	_, _, _ = api.Purchase(ctx, "test.test")
+	_, _, _ = api.Preview(ctx, "fail.test")
+	_, _, _ = api.Preview(ctx, "")
+
+	if len(metrics.observed) != 3 {
+		t.Fatalf("observed = %+v", metrics.observed)
+	}
+
+	m := metrics.observed[0]
+	if m.Mode != modePurchase || m.StatusClass != "2xx" || m.ErrorKind != "" || m.Records != 2 ||
+		m.BytesRead != int64(len(resp)) || m.Duration <= 0 {
+		t.Errorf("purchase metrics = %+v", m)
+	}
+	if m := metrics.observed[1]; m.Mode != modePreview || m.StatusClass != "4xx" || m.ErrorKind != "unauthorized" {
+		t.Errorf("failed preview metrics = %+v", m)
+	}
+	if m := metrics.observed[2]; m.StatusClass != "none" || m.ErrorKind != "invalid_domain" {
+		t.Errorf("invalid argument metrics = %+v", m)
+	}
+}
+
+func TestErrorKind(t *testing.T) {
+	var syntaxErr error = &json.SyntaxError{}
+
+	tests := []struct {
+		err  error
+		want string
+	}{
+		{err: nil, want: ""},
+		{err: context.Canceled, want: "canceled"},
+		{err: &ErrorMessage{Code: 403}, want: "insufficient_credits"},
+		{err: &ErrorMessage{Code: 123}, want: "api"},
+		{err: &ErrorResponse{Response: &http.Response{StatusCode: 404}}, want: "http"},
+		{err: &BudgetError{}, want: "budget"},
+		{err: &ArgError{"Dir", "cannot be empty"}, want: "argument"},
+		{err: errors.New("cannot parse response: " + syntaxErr.Error()), want: "other"},
+		{err: syntaxErr, want: "decode"},
+	}
+	for _, tt := range tests {
+		if got := ErrorKind(tt.err); got != tt.want {
+			t.Errorf("ErrorKind(%v) = %q, want %q", tt.err, got, tt.want)
+		}
+	}
+}
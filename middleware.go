@@ -0,0 +1,56 @@
+package whoishistory
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+)
+
+// Doer sends an HTTP request and returns an HTTP response.
+// The request context is available with req.Context().
+type Doer func(req *http.Request) (*http.Response, error)
+
+// Middleware wraps Doer to inspect or modify requests and responses.
+// It's applied to every attempt made by Client.Do.
+type Middleware func(next Doer) Doer
+
+// chain wraps the doer with the middleware. The first middleware is the outermost:
+// it sees the request first and the response last.
+func chain(doer Doer, middleware []Middleware) Doer {
+	for i := len(middleware) - 1; i >= 0; i-- {
+		doer = middleware[i](doer)
+	}
+	return doer
+}
+
+// Outcome is the result of a HistoricService call passed to OutcomeHook.
+type Outcome struct {
+	// Mode is either "preview" or "purchase".
+	Mode   string
+	Domain string
+	// Query holds the request parameters except the API key.
+	// It's nil if the request was not built.
+	Query        url.Values
+	RecordsCount int
+	// Records are the purchased records. It's nil in preview mode.
+	Records  []*WhoisRecord
+	Response *Response
+	Err      error
+}
+
+// OutcomeHook is called after every HistoricService call.
+// Hooks are called in order and may modify the outcome:
+// the call returns the records, the count and the error left by the last hook.
+type OutcomeHook func(ctx context.Context, outcome *Outcome)
+
+// outcomeQuery returns query parameters safe to expose.
+func outcomeQuery(q url.Values) url.Values {
+	v := make(url.Values, len(q))
+	for k, vv := range q {
+		if k == "apiKey" {
+			continue
+		}
+		v[k] = append([]string(nil), vv...)
+	}
+	return v
+}
@@ -0,0 +1,165 @@
+package whoishistory
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newMiddlewareAPI(t *testing.T, params ClientParams) (*Client, func()) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Header.Get("X-Signature") != "signed" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_, _ = w.Write([]byte(`{"recordsCount":2,"records":[{"domainName":"a.test"},{"domainName":"b.test"}]}`))
+	}))
+
+	apiURL, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	params.HTTPClient = server.Client()
+	params.HistoricBaseURL = apiURL
+
+	return NewClient(apiKey, params), server.Close
+}
+
+func TestMiddlewareOrder(t *testing.T) {
+	var calls []string
+
+	named := func(name string) Middleware {
+		return func(next Doer) Doer {
+			return func(req *http.Request) (*http.Response, error) {
+				calls = append(calls, name+" request")
+				resp, err := next(req)
+				calls = append(calls, name+" response")
+				return resp, err
+			}
+		}
+	}
+	sign := func(next Doer) Doer {
+		return func(req *http.Request) (*http.Response, error) {
+			req.Header.Set("X-Signature", "signed")
+			return next(req)
+		}
+	}
+
+	api, cleanup := newMiddlewareAPI(t, ClientParams{
+		Middleware: []Middleware{named("first"), named("second"), sign},
+	})
+	defer cleanup()
+
+	if _, _, err := api.Preview(context.Background(), "test.test"); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{"first request", "second request", "second response", "first response"}
+	if !reflect.DeepEqual(calls, want) {
+		t.Errorf("calls = %v, want %v", calls, want)
+	}
+}
+
+func TestMiddlewareFaultInjection(t *testing.T) {
+	failures := 2
+	inject := func(next Doer) Doer {
+		return func(req *http.Request) (*http.Response, error) {
+			if failures > 0 {
+				failures--
+				return &http.Response{
+					StatusCode: http.StatusServiceUnavailable,
+					Header:     http.Header{},
+					Body:       ioutil.NopCloser(strings.NewReader("")),
+					Request:    req,
+				}, nil
+			}
+			req.Header.Set("X-Signature", "signed")
+			return next(req)
+		}
+	}
+
+	api, cleanup := newMiddlewareAPI(t, ClientParams{
+		Middleware: []Middleware{inject},
+		Retry:      &RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond},
+	})
+	defer cleanup()
+
+	_, resp, err := api.Preview(context.Background(), "test.test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Attempts != 3 {
+		t.Errorf("attempts = %v, want 3", resp.Attempts)
+	}
+}
+
+func TestOutcomeHooks(t *testing.T) {
+	errFiltered := errors.New("filtered")
+
+	var seen []Outcome
+	record := func(ctx context.Context, o *Outcome) {
+		seen = append(seen, *o)
+	}
+	filter := func(ctx context.Context, o *Outcome) {
+		if o.Err != nil || o.Mode != modePurchase {
+			return
+		}
+		o.Records = o.Records[:1]
+		o.RecordsCount = 1
+		if o.Domain == "filtered.test" {
+			o.Err = errFiltered
+		}
+	}
+
+	api, cleanup := newMiddlewareAPI(t, ClientParams{
+		Middleware: []Middleware{func(next Doer) Doer {
+			return func(req *http.Request) (*http.Response, error) {
+				req.Header.Set("X-Signature", "signed")
+				return next(req)
+			}
+		}},
+		OutcomeHooks: []OutcomeHook{record, filter, record},
+	})
+	defer cleanup()
+
+	ctx := context.Background()
+
+	n, _, err := api.Preview(ctx, "test.test")
+	if err != nil || n != 2 {
+		t.Errorf("Preview() = %v, %v", n, err)
+	}
+
+	records, _, err := api.Purchase(ctx, "test.test")
+	if err != nil || len(records) != 1 {
+		t.Errorf("Purchase() = %v, %v", records, err)
+	}
+
+	_, _, err = api.Purchase(ctx, "filtered.test")
+	if !errors.Is(err, errFiltered) {
+		t.Errorf("Purchase() error = %v, want %v", err, errFiltered)
+	}
+
+	_, _, err = api.Purchase(ctx, "")
+	checkErr(t, err, `invalid argument: "name" cannot be empty`)
+
+	if len(seen) != 8 {
+		t.Fatalf("hooks called %d times, want 8", len(seen))
+	}
+	if o := seen[2]; o.Mode != modePurchase || o.RecordsCount != 2 || len(o.Records) != 2 ||
+		o.Query.Get("mode") != modePurchase || o.Query.Get("apiKey") != "" {
+		t.Errorf("purchase outcome = %+v", o)
+	}
+	if o := seen[3]; len(o.Records) != 1 {
+		t.Errorf("second hook did not see filtered records: %+v", o)
+	}
+	if o := seen[6]; o.Err == nil || o.Query != nil || o.Response != nil {
+		t.Errorf("invalid argument outcome = %+v", o)
+	}
+}
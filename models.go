@@ -3,6 +3,7 @@ package whoishistory
 import (
 	"encoding/json"
 	"fmt"
+	"strconv"
 	"time"
 )
 
@@ -20,30 +21,94 @@ type Time time.Time
 
 var emptyTime Time
 
-// UnmarshalJSON decodes time as historic whois API does
+// apiTimeLayout is the layout of times encoded by MarshalJSON
+const apiTimeLayout = "2006-01-02T15:04:05-07:00"
+
+// TimeLayouts are layouts tried in order by ParseTime.
+// Fractional seconds are accepted by every layout with seconds.
+// Values without time zone are considered UTC.
+var TimeLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04:05-0700",
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05 MST",
+	"2006-01-02 15:04:05Z07:00",
+	"2006-01-02 15:04:05",
+	"2006-01-02",
+}
+
+// TimeError is returned when a time value matches none of TimeLayouts.
+type TimeError struct {
+	Value string
+}
+
+func (e *TimeError) Error() string {
+	return "cannot parse time " + strconv.Quote(e.Value)
+}
+
+// ParseTime parses the value with the first matching layout of TimeLayouts.
+// Empty value results in zero time.
+func ParseTime(value string) (Time, error) {
+	if value == "" {
+		return emptyTime, nil
+	}
+	for _, layout := range TimeLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return Time(t), nil
+		}
+	}
+	return emptyTime, &TimeError{Value: value}
+}
+
+// UnmarshalJSON decodes time in any of TimeLayouts
 func (t *Time) UnmarshalJSON(b []byte) error {
 	str, err := unmarshalString(b)
 	if err != nil {
 		return err
 	}
-	if str == "" {
-		*t = emptyTime
-		return nil
-	}
-	v, err := time.Parse("2006-01-02T15:04:05-07:00", str)
+	v, err := ParseTime(str)
 	if err != nil {
 		return err
 	}
-	*t = Time(v)
+	*t = v
 	return nil
 }
 
 // MarshalJSON encodes time as historic whois API does
 func (t Time) MarshalJSON() ([]byte, error) {
-	if t == emptyTime {
+	if t.IsZero() {
 		return []byte(`""`), nil
 	}
-	return []byte(`"` + time.Time(t).Format("2006-01-02T15:04:05-07:00") + `"`), nil
+	return []byte(`"` + t.String() + `"`), nil
+}
+
+// IsZero reports whether the time is not set.
+func (t Time) IsZero() bool {
+	return time.Time(t).IsZero()
+}
+
+// Std returns the time as time.Time.
+func (t Time) Std() time.Time {
+	return time.Time(t)
+}
+
+// String returns the time in the format of historic whois API
+// or empty string if the time is not set.
+func (t Time) String() string {
+	if t.IsZero() {
+		return ""
+	}
+	return time.Time(t).Format(apiTimeLayout)
+}
+
+// Before reports whether the time is before u.
+func (t Time) Before(u Time) bool {
+	return time.Time(t).Before(time.Time(u))
+}
+
+// After reports whether the time is after u.
+func (t Time) After(u Time) bool {
+	return time.Time(t).After(time.Time(u))
 }
 
 // Audit is a part of whois API response. It represents dates
@@ -92,6 +157,15 @@ type WhoisRecord struct {
 	TechnicalContact      Contact  `json:"technicalContact"`
 	BillingContact        Contact  `json:"billingContact"`
 	ZoneContact           Contact  `json:"zoneContact"`
+	// InvalidTimes lists time values skipped by lenient decoding.
+	InvalidTimes []InvalidTime `json:"invalidTimes,omitempty"`
+}
+
+// InvalidTime is a time value of the record which cannot be parsed.
+type InvalidTime struct {
+	// Field is the JSON path of the value like "audit.updatedDate".
+	Field string `json:"field"`
+	Value string `json:"value"`
 }
 
 // ErrorMessage is a error message from historic whois API
@@ -103,3 +177,8 @@ type ErrorMessage struct {
 func (e ErrorMessage) Error() string {
 	return fmt.Sprintf("API error: [%d] %s", e.Code, e.Message)
 }
+
+// Is reports whether the API error code corresponds to the target sentinel error.
+func (e ErrorMessage) Is(target error) bool {
+	return target != nil && errorForCode(e.Code) == target
+}
@@ -2,12 +2,15 @@ package whoishistory
 
 import (
 	"encoding/json"
+	"reflect"
 	"testing"
+	"time"
 )
 
 func TestTime(t *testing.T) {
 	tests := []struct {
 		name   string
+		output string
 		decErr string
 		encErr string
 	}{
@@ -21,9 +24,29 @@ func TestTime(t *testing.T) {
 			decErr: "",
 			encErr: "",
 		},
+		{
+			name:   `"2006-01-02T15:04:05Z"`,
+			output: `"2006-01-02T15:04:05+00:00"`,
+		},
+		{
+			name:   `"2006-01-02T15:04:05.123456Z"`,
+			output: `"2006-01-02T15:04:05+00:00"`,
+		},
+		{
+			name:   `"2006-01-02T15:04:05+0100"`,
+			output: `"2006-01-02T15:04:05+01:00"`,
+		},
+		{
+			name:   `"2006-01-02 15:04:05 UTC"`,
+			output: `"2006-01-02T15:04:05+00:00"`,
+		},
+		{
+			name:   `"2006-01-02"`,
+			output: `"2006-01-02T00:00:00+00:00"`,
+		},
 		{
 			name:   `"2006-01-02T15:04:05Z08:00"`,
-			decErr: `parsing time "2006-01-02T15:04:05Z08:00" as "2006-01-02T15:04:05-07:00": cannot parse "" as "-07:00"`,
+			decErr: `cannot parse time "2006-01-02T15:04:05Z08:00"`,
 			encErr: "",
 		},
 		{
@@ -49,13 +72,70 @@ func TestTime(t *testing.T) {
 				return
 			}
 
-			if string(bb) != tt.name {
-				t.Errorf("got = %v, want %v", string(bb), tt.name)
+			want := tt.output
+			if want == "" {
+				want = tt.name
+			}
+			if string(bb) != want {
+				t.Errorf("got = %v, want %v", string(bb), want)
 			}
 		})
 	}
 }
 
+func TestTimeHelpers(t *testing.T) {
+	early, err := ParseTime("2020-01-01")
+	if err != nil {
+		t.Fatal(err)
+	}
+	late, err := ParseTime("2020-01-01 10:00:00")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if early.IsZero() || !emptyTime.IsZero() {
+		t.Error("IsZero() is wrong")
+	}
+	if !early.Before(late) || early.After(late) || !late.After(early) {
+		t.Error("Before() or After() is wrong")
+	}
+	if !early.Std().Equal(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("Std() = %v", early.Std())
+	}
+	if early.String() != "2020-01-01T00:00:00+00:00" || emptyTime.String() != "" {
+		t.Errorf("String() = %q", early.String())
+	}
+}
+
+func TestDecodeRecordLenient(t *testing.T) {
+	const raw = `{"domainName":"a.test","createdDateISO8601":"yesterday",` +
+		`"updatedDateISO8601":"2020-01-02T00:00:00Z","audit":{"createdDate":"2020-01-01","updatedDate":"soon"}}`
+
+	var rec WhoisRecord
+	checkErr(t, json.Unmarshal([]byte(raw), &rec), `cannot parse time "yesterday"`)
+
+	got, err := decodeRecordLenient([]byte(raw))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.DomainName != "a.test" || !got.CreatedDateISO8601.IsZero() ||
+		got.UpdatedDateISO8601.String() != "2020-01-02T00:00:00+00:00" ||
+		got.Audit.CreatedDate.String() != "2020-01-01T00:00:00+00:00" {
+		t.Errorf("record = %+v", got)
+	}
+
+	want := []InvalidTime{
+		{Field: "createdDateISO8601", Value: "yesterday"},
+		{Field: "audit.updatedDate", Value: "soon"},
+	}
+	if !reflect.DeepEqual(got.InvalidTimes, want) {
+		t.Errorf("InvalidTimes = %+v, want %+v", got.InvalidTimes, want)
+	}
+
+	_, err = decodeRecordLenient([]byte(`{"domainName":1}`))
+	checkErr(t, err, "json: cannot unmarshal number into Go struct field WhoisRecord.domainName of type string")
+}
+
 func TestContact(t *testing.T) {
 	tests := []struct {
 		name   string
@@ -0,0 +1,196 @@
+package whoishistory
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// NormalizedContact is a contact with values in canonical forms.
+// Values which cannot be normalized are empty.
+type NormalizedContact struct {
+	Name         string `json:"name"`
+	Organization string `json:"organization"`
+	Street       string `json:"street"`
+	City         string `json:"city"`
+	State        string `json:"state"`
+	PostalCode   string `json:"postalCode"`
+	// CountryCode is the ISO 3166-1 alpha-2 code of the country.
+	CountryCode string `json:"countryCode"`
+	// Email is the lowercased email split into EmailLocal and EmailDomain.
+	Email       string `json:"email"`
+	EmailLocal  string `json:"emailLocal"`
+	EmailDomain string `json:"emailDomain"`
+	// Telephone and Fax are numbers in E.164 format like +15555551234.
+	Telephone    string `json:"telephone"`
+	TelephoneExt string `json:"telephoneExt"`
+	Fax          string `json:"fax"`
+	FaxExt       string `json:"faxExt"`
+}
+
+// Normalize converts values of the contact to canonical forms.
+// It works offline, country names are looked up in the embedded ISO 3166-1 table.
+func (c *Contact) Normalize() NormalizedContact {
+	n := NormalizedContact{
+		Name:         collapseSpace(c.Name),
+		Organization: collapseSpace(c.Organization),
+		Street:       collapseSpace(c.Street),
+		City:         collapseSpace(c.City),
+		State:        collapseSpace(c.State),
+		PostalCode:   collapseSpace(c.PostalCode),
+		CountryCode:  NormalizeCountry(c.Country),
+		Email:        NormalizeEmail(c.Email),
+	}
+	if i := strings.LastIndex(n.Email, "@"); i >= 0 {
+		n.EmailLocal, n.EmailDomain = n.Email[:i], n.Email[i+1:]
+	}
+	n.Telephone, n.TelephoneExt = normalizePhoneExt(c.Telephone, c.TelephoneExt)
+	n.Fax, n.FaxExt = normalizePhoneExt(c.Fax, c.FaxExt)
+	return n
+}
+
+// collapseSpace trims the value and replaces runs of whitespace with single spaces.
+func collapseSpace(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// phoneExtPattern matches the extension at the end of a phone number.
+var phoneExtPattern = regexp.MustCompile(`(?i)\s*(?:ext\.?|extension|x|#)\s*(\d+)\s*$`)
+
+// E.164 allows up to 15 digits including the country code,
+// shorter numbers cannot have the country code.
+const (
+	minPhoneDigits = 8
+	maxPhoneDigits = 15
+)
+
+// NormalizePhone converts the phone number to E.164 format and returns it with the extension.
+// It accepts numbers like "+1.5555551234", "+1 (555) 555-1234 ext. 12" and "0015555551234".
+// Numbers without the international prefix "+" or "00" are returned as empty strings
+// since their country code is unknown, as well as other invalid numbers.
+func NormalizePhone(s string) (number, ext string) {
+	s = strings.TrimSpace(s)
+	if m := phoneExtPattern.FindStringSubmatchIndex(s); m != nil {
+		ext = s[m[2]:m[3]]
+		s = s[:m[0]]
+	}
+
+	switch {
+	case strings.HasPrefix(s, "+"):
+		s = s[1:]
+	case strings.HasPrefix(s, "00"):
+		s = s[2:]
+	default:
+		return "", ""
+	}
+
+	digits := make([]rune, 0, len(s))
+	for _, r := range s {
+		switch {
+		case r >= '0' && r <= '9':
+			digits = append(digits, r)
+		case strings.ContainsRune(" .-()/", r):
+		default:
+			return "", ""
+		}
+	}
+
+	if len(digits) < minPhoneDigits || len(digits) > maxPhoneDigits || digits[0] == '0' {
+		return "", ""
+	}
+	return "+" + string(digits), ext
+}
+
+// normalizePhoneExt normalizes the phone number preferring the extension
+// from the separate field of the contact.
+func normalizePhoneExt(phone, ext string) (string, string) {
+	number, parsed := NormalizePhone(phone)
+	if ext = strings.TrimSpace(ext); ext == "" || number == "" {
+		ext = parsed
+	}
+	return number, ext
+}
+
+// maxEmailLocalLength is the limit of the local part of emails.
+const maxEmailLocalLength = 64
+
+// emailLocalPattern matches unquoted local parts of emails.
+var emailLocalPattern = regexp.MustCompile("^[a-z0-9!#$%&'*+/=?^_`{|}~-]+(\\.[a-z0-9!#$%&'*+/=?^_`{|}~-]+)*$")
+
+// NormalizeEmail lowercases the email and validates it.
+// The "mailto:" prefix is dropped and the domain is normalized with NormalizeDomain.
+// Invalid emails are returned as empty strings.
+func NormalizeEmail(s string) string {
+	s = strings.ToLower(strings.TrimSpace(s))
+	s = strings.TrimPrefix(s, "mailto:")
+
+	i := strings.LastIndex(s, "@")
+	if i < 0 {
+		return ""
+	}
+	local, domain := s[:i], s[i+1:]
+	if len(local) > maxEmailLocalLength || !emailLocalPattern.MatchString(local) {
+		return ""
+	}
+	// NormalizeDomain accepts URLs and trailing dots which are not valid in emails
+	if strings.ContainsAny(domain, " \t/?#:@") || strings.HasSuffix(domain, ".") || !strings.Contains(domain, ".") {
+		return ""
+	}
+	domain, err := NormalizeDomain(domain)
+	if err != nil {
+		return ""
+	}
+	return local + "@" + domain
+}
+
+// countryCodes maps normalized country names and codes to ISO 3166-1 alpha-2 codes.
+var countryCodes = countryIndex()
+
+func countryIndex() map[string]string {
+	index := make(map[string]string)
+	for _, c := range countries {
+		index[countryKey(c.alpha2)] = c.alpha2
+		index[countryKey(c.alpha3)] = c.alpha2
+		index[countryKey(c.name)] = c.alpha2
+		for _, alias := range c.aliases {
+			index[countryKey(alias)] = c.alpha2
+		}
+	}
+	return index
+}
+
+// countryKey uppercases the name, drops dots, apostrophes and the leading article,
+// expands "St" to "Saint" and replaces other punctuation with single spaces.
+func countryKey(s string) string {
+	s = strings.Map(func(r rune) rune {
+		switch {
+		case r == '.' || r == '\'' || r == '’':
+			return -1
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			return unicode.ToUpper(r)
+		default:
+			return ' '
+		}
+	}, s)
+
+	words := strings.Fields(s)
+	if len(words) > 1 && words[0] == "THE" {
+		words = words[1:]
+	}
+	// a single "ST" is the code of Sao Tome and Principe
+	if len(words) > 1 {
+		for i, w := range words {
+			if w == "ST" {
+				words[i] = "SAINT"
+			}
+		}
+	}
+	return strings.Join(words, " ")
+}
+
+// NormalizeCountry returns the ISO 3166-1 alpha-2 code of the country given by
+// the alpha-2 or alpha-3 code, the short or official name or a common alternative name
+// like "UK" or "South Korea" in any case. It returns empty string for unknown countries.
+func NormalizeCountry(s string) string {
+	return countryCodes[countryKey(s)]
+}
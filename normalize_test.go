@@ -0,0 +1,144 @@
+package whoishistory
+
+import (
+	"testing"
+)
+
+func TestNormalizePhone(t *testing.T) {
+	tests := []struct {
+		phone, number, ext string
+	}{
+		{"+1.5555551234", "+15555551234", ""},
+		{"15555551234", "", ""},
+		{"(555) 555-1234", "", ""},
+		{"+1 (555) 555-1234 ext. 12", "+15555551234", "12"},
+		{"+44.2079460000x7", "+442079460000", "7"},
+		{"0049 30 1234567", "+49301234567", ""},
+		{"555-1234", "", ""},
+		{"+1.555CALLNOW", "", ""},
+		{"+1.5555551234567890", "", ""},
+		{"", "", ""},
+	}
+
+	for _, tt := range tests {
+		number, ext := NormalizePhone(tt.phone)
+		if number != tt.number || ext != tt.ext {
+			t.Errorf("NormalizePhone(%q) = %q, %q, want %q, %q", tt.phone, number, ext, tt.number, tt.ext)
+		}
+	}
+}
+
+func TestNormalizeCountry(t *testing.T) {
+	tests := []struct {
+		country, want string
+	}{
+		{"US", "US"},
+		{"us", "US"},
+		{"USA", "US"},
+		{"UNITED STATES", "US"},
+		{"United States of America", "US"},
+		{"U.S.", "US"},
+		{" united  kingdom ", "GB"},
+		{"UK", "GB"},
+		{"Korea, Republic of", "KR"},
+		{"South Korea", "KR"},
+		{"The Netherlands", "NL"},
+		{"Côte d'Ivoire", "CI"},
+		{"COTE D'IVOIRE", "CI"},
+		{"Russian Federation", "RU"},
+		{"Russia", "RU"},
+		{"RUSSIA", "RU"},
+		{"Deutschland", "DE"},
+		{"España", "ES"},
+		{"Schweiz", "CH"},
+		{"Brasil", "BR"},
+		{"Turkey", "TR"},
+		{"St. Lucia", "LC"},
+		{"St Kitts", "KN"},
+		{"ST", "ST"},
+		{"Macau", "MO"},
+		{"Atlantis", ""},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		if got := NormalizeCountry(tt.country); got != tt.want {
+			t.Errorf("NormalizeCountry(%q) = %q, want %q", tt.country, got, tt.want)
+		}
+	}
+}
+
+func TestCountryIndex(t *testing.T) {
+	seen := make(map[string]string)
+	for _, c := range countries {
+		for _, name := range append([]string{c.alpha2, c.alpha3, c.name}, c.aliases...) {
+			key := countryKey(name)
+			if code, ok := seen[key]; ok && code != c.alpha2 {
+				t.Errorf("%q is both %s and %s", name, code, c.alpha2)
+			}
+			seen[key] = c.alpha2
+		}
+	}
+}
+
+func TestNormalizeEmail(t *testing.T) {
+	tests := []struct {
+		email, want string
+	}{
+		{"John.Smith@Example.COM", "john.smith@example.com"},
+		{" mailto:admin+whois@example.com ", "admin+whois@example.com"},
+		{"info@bücher.example", "info@xn--bcher-kva.example"},
+		{"john@localhost", ""},
+		{"john..smith@example.com", ""},
+		{".john@example.com", ""},
+		{"john smith@example.com", ""},
+		{"john@example.com/path", ""},
+		{"john@example.com.", ""},
+		{"REDACTED FOR PRIVACY", ""},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		if got := NormalizeEmail(tt.email); got != tt.want {
+			t.Errorf("NormalizeEmail(%q) = %q, want %q", tt.email, got, tt.want)
+		}
+	}
+}
+
+func TestContactNormalize(t *testing.T) {
+	c := Contact{
+		Name:         " John   Smith ",
+		Street:       "1 Main St.\n  Suite 100",
+		City:         "  New   York ",
+		State:        "NY",
+		PostalCode:   "10001",
+		Country:      "UNITED STATES",
+		Email:        "John.Smith@Example.com",
+		Telephone:    "+1.5555551234",
+		TelephoneExt: "42",
+		Fax:          "+1.5555554321 ext 7",
+	}
+
+	want := NormalizedContact{
+		Name:         "John Smith",
+		Street:       "1 Main St. Suite 100",
+		City:         "New York",
+		State:        "NY",
+		PostalCode:   "10001",
+		CountryCode:  "US",
+		Email:        "john.smith@example.com",
+		EmailLocal:   "john.smith",
+		EmailDomain:  "example.com",
+		Telephone:    "+15555551234",
+		TelephoneExt: "42",
+		Fax:          "+15555554321",
+		FaxExt:       "7",
+	}
+	if got := c.Normalize(); got != want {
+		t.Errorf("Normalize() = %+v, want %+v", got, want)
+	}
+
+	if got := (&Contact{}).Normalize(); got != (NormalizedContact{}) {
+		t.Errorf("Normalize() = %+v", got)
+	}
+}
@@ -0,0 +1,143 @@
+package whoishistory
+
+import (
+	"strings"
+)
+
+// Confidence is the level of confidence of a detected ownership change.
+type Confidence string
+
+// Levels of confidence.
+const (
+	ConfidenceLow    Confidence = "low"
+	ConfidenceMedium Confidence = "medium"
+	ConfidenceHigh   Confidence = "high"
+)
+
+// Evidence is a changed field supporting an ownership change.
+type Evidence struct {
+	// Field is the field name like "registrantContact.email".
+	Field string `json:"field"`
+	Old   string `json:"old"`
+	New   string `json:"new"`
+}
+
+// OwnershipChange is a detected change of the registrant of a domain.
+type OwnershipChange struct {
+	// Time is the time of the first snapshot showing the new registrant.
+	Time Time
+	// Old is the last record before the change and New is the first one after it.
+	Old *WhoisRecord
+	New *WhoisRecord
+
+	Confidence Confidence
+	// Evidence lists changed registrant fields followed by
+	// the registrar name if the domain was transferred at the same time.
+	Evidence []Evidence
+}
+
+// registrant holds the last known registrant fields.
+type registrant struct {
+	name, organization, email string
+}
+
+// newRegistrant takes fields of the contact carrying real data.
+func newRegistrant(c *Contact) registrant {
+	return registrant{
+		name:         realValue("name", c.Name),
+		organization: realValue("organization", c.Organization),
+		email:        realValue("email", c.Email),
+	}
+}
+
+// update replaces the known fields with fields of the contact carrying real data.
+func (r *registrant) update(c *Contact) {
+	cur := newRegistrant(c)
+	if strings.TrimSpace(cur.name) != "" {
+		r.name = cur.name
+	}
+	if strings.TrimSpace(cur.organization) != "" {
+		r.organization = cur.organization
+	}
+	if strings.TrimSpace(cur.email) != "" {
+		r.email = cur.email
+	}
+}
+
+// compareRegistrants returns changed fields and the number of fields known to be unchanged.
+// Fields empty in either registrant are unknown. Cosmetic differences are ignored.
+func compareRegistrants(old, cur registrant) (evidence []Evidence, unchanged int) {
+	for _, f := range []struct {
+		field    string
+		old, cur string
+	}{
+		{"registrantContact.name", old.name, cur.name},
+		{"registrantContact.organization", old.organization, cur.organization},
+		{"registrantContact.email", old.email, cur.email},
+	} {
+		o, c := normalizeText(f.old), normalizeText(f.cur)
+		switch {
+		case o == "" || c == "":
+			continue
+		case o == c:
+			unchanged++
+		default:
+			evidence = append(evidence, Evidence{Field: f.field, Old: f.old, New: f.cur})
+		}
+	}
+	return evidence, unchanged
+}
+
+// registrantChanged reports whether the registrant of the contacts differs.
+func registrantChanged(old, cur *Contact) bool {
+	evidence, _ := compareRegistrants(newRegistrant(old), newRegistrant(cur))
+	return len(evidence) > 0
+}
+
+// DetectOwnershipChanges finds changes of the registrant in records of one domain.
+// Records are ordered as in NewTimeline. Registrant name, organization and email are
+// compared with their last known real values ignoring case and whitespace.
+// Values redacted or belonging to privacy services according to DefaultPrivacyClassifier
+// are unknown, so rotation of masked emails and redaction are not changes.
+//
+// Confidence is high if two or more fields changed, medium if one field changed and
+// the others are unknown or the registrar changed too, and low otherwise.
+func DetectOwnershipChanges(records []*WhoisRecord) []OwnershipChange {
+	var changes []OwnershipChange
+
+	var known registrant
+	var prev *WhoisRecord
+	for _, s := range NewTimeline(records).Snapshots {
+		rec := s.Record
+		if prev == nil {
+			known.update(&rec.RegistrantContact)
+			prev = rec
+			continue
+		}
+
+		evidence, unchanged := compareRegistrants(known, newRegistrant(&rec.RegistrantContact))
+		if len(evidence) > 0 {
+			change := OwnershipChange{Time: s.First, Old: prev, New: rec, Evidence: evidence}
+
+			transferred := textChanged(prev.RegistrarName, rec.RegistrarName)
+			if transferred {
+				change.Evidence = append(change.Evidence, Evidence{Field: "registrarName", Old: prev.RegistrarName, New: rec.RegistrarName})
+			}
+
+			switch {
+			case len(evidence) >= 2:
+				change.Confidence = ConfidenceHigh
+			case unchanged == 0 || transferred:
+				change.Confidence = ConfidenceMedium
+			default:
+				change.Confidence = ConfidenceLow
+			}
+			changes = append(changes, change)
+		}
+
+		known.update(&rec.RegistrantContact)
+		prev = rec
+	}
+
+	return changes
+}
@@ -0,0 +1,78 @@
+package whoishistory
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDetectOwnershipChanges(t *testing.T) {
+	record := func(audit, registrar string, registrant Contact) *WhoisRecord {
+		return &WhoisRecord{
+			DomainName:        "a.test",
+			Audit:             Audit{UpdatedDate: mustTime(t, audit)},
+			RegistrarName:     registrar,
+			RegistrantContact: registrant,
+		}
+	}
+
+	records := []*WhoisRecord{
+		record("2010-01-01", "A", Contact{Name: "John Smith", Organization: "Acme", Email: "f3a9@domainsbyproxy.com"}),
+		// cosmetic changes and rotation of the masked email
+		record("2011-01-01", "A", Contact{Name: " john  smith", Organization: "ACME", Email: "77b2@DomainsByProxy.com"}),
+		// the redacted name and the missing organization are unknown
+		record("2012-01-01", "A", Contact{Name: "REDACTED FOR PRIVACY", Email: "jane@example.com"}),
+		// name changed while the organization is the same as last known
+		record("2013-01-01", "A", Contact{Name: "Jane Doe", Organization: "Acme"}),
+		record("2014-01-01", "B", Contact{Name: "Bob", Organization: "Bob LLC", Email: "bob@example.com"}),
+		record("2015-01-01", "C", Contact{Name: "Alice", Organization: "Bob LLC", Email: "bob@example.com"}),
+	}
+
+	changes := DetectOwnershipChanges(records)
+
+	type change struct {
+		Time       string
+		Old, New   *WhoisRecord
+		Confidence Confidence
+		Evidence   []Evidence
+	}
+	// the email of the privacy service is unknown, so its replacement
+	// with jane@example.com in 2012 is a privacy change, not an ownership change
+	want := []change{
+		{
+			Time: "2013-01-01T00:00:00+00:00", Old: records[2], New: records[3], Confidence: ConfidenceLow,
+			Evidence: []Evidence{{Field: "registrantContact.name", Old: " john  smith", New: "Jane Doe"}},
+		},
+		{
+			Time: "2014-01-01T00:00:00+00:00", Old: records[3], New: records[4], Confidence: ConfidenceHigh,
+			Evidence: []Evidence{
+				{Field: "registrantContact.name", Old: "Jane Doe", New: "Bob"},
+				{Field: "registrantContact.organization", Old: "Acme", New: "Bob LLC"},
+				{Field: "registrantContact.email", Old: "jane@example.com", New: "bob@example.com"},
+				{Field: "registrarName", Old: "A", New: "B"},
+			},
+		},
+		{
+			Time: "2015-01-01T00:00:00+00:00", Old: records[4], New: records[5], Confidence: ConfidenceMedium,
+			Evidence: []Evidence{
+				{Field: "registrantContact.name", Old: "Bob", New: "Alice"},
+				{Field: "registrarName", Old: "B", New: "C"},
+			},
+		},
+	}
+
+	var got []change
+	for _, c := range changes {
+		got = append(got, change{c.Time.String(), c.Old, c.New, c.Confidence, c.Evidence})
+	}
+	if !reflect.DeepEqual(got, want) {
+		for _, c := range got {
+			t.Logf("%s %s %+v", c.Time, c.Confidence, c.Evidence)
+		}
+		t.Fatal("unexpected changes")
+	}
+
+	tl := NewTimeline(records[:3])
+	if events := tl.EventsOf(EventOwnershipChange); len(events) != 0 {
+		t.Errorf("timeline reports masked values as ownership change: %+v", events)
+	}
+}
@@ -0,0 +1,243 @@
+package whoishistory
+
+import (
+	"regexp"
+	"strings"
+)
+
+// FieldClass tells whether a contact field carries real data.
+type FieldClass string
+
+// Classes of contact fields.
+const (
+	FieldEmpty FieldClass = "empty"
+	// FieldRedacted means that the value is hidden by the registry or registrar.
+	FieldRedacted FieldClass = "redacted"
+	// FieldPrivacy means that the value belongs to a privacy or proxy service.
+	FieldPrivacy FieldClass = "privacy"
+	// FieldData means that the value is real data.
+	FieldData FieldClass = "data"
+)
+
+// PrivacyService is a privacy or proxy service recognized in contact values.
+type PrivacyService struct {
+	Name string
+	// Pattern matches lowercased values of contact fields.
+	Pattern *regexp.Regexp
+	// Fields are JSON names of contact fields the pattern applies to.
+	// Nil means all fields.
+	Fields []string
+}
+
+// identityFields are contact fields which identify the registrant.
+var identityFields = []string{"name", "organization", "email"}
+
+// DefaultPrivacyServices are privacy services known to the package.
+// More specific services go first, the last one matches service-style phrases
+// like "privacy service" or "proxy registration" in identity fields.
+var DefaultPrivacyServices = []PrivacyService{
+	{Name: "Domains By Proxy", Pattern: regexp.MustCompile(`domains\s*by\s*proxy`)},
+	{Name: "WhoisGuard", Pattern: regexp.MustCompile(`whois\s*guard`)},
+	{Name: "Privacy Guardian", Pattern: regexp.MustCompile(`privacy\s*guardian`)},
+	{Name: "Contact Privacy", Pattern: regexp.MustCompile(`contact\s*privacy`)},
+	{Name: "Withheld for Privacy", Pattern: regexp.MustCompile(`withheld\s*for\s*privacy`)},
+	{Name: "Whois Privacy Service", Pattern: regexp.MustCompile(`whois\s*privacy\s*service`)},
+	{Name: "Perfect Privacy", Pattern: regexp.MustCompile(`perfect\s*privacy`)},
+	{Name: "Super Privacy Service", Pattern: regexp.MustCompile(`super\s*privacy`)},
+	{Name: "Privacy Protect", Pattern: regexp.MustCompile(`privacy\s*protect`)},
+	{Name: "Identity Protection Service", Pattern: regexp.MustCompile(`identity\s*protection\s*service`)},
+	{Name: "Whois Agent", Pattern: regexp.MustCompile(`whois\s*agent`)},
+	{
+		Name: "Privacy service",
+		Pattern: regexp.MustCompile(`\b(privacy|proxy)[\s-]*(services?|protection|registration|protected)\b|` +
+			`\bwhois\s*(privacy|protection)\b|\b(domain|registrant|identity)\s+(is\s+)?protected\b`),
+		Fields: identityFields,
+	},
+}
+
+// DefaultRedactedPatterns match lowercased values hidden by registries and registrars.
+var DefaultRedactedPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`redacted`),
+	regexp.MustCompile(`not\s*disclosed`),
+	regexp.MustCompile(`non-public`),
+	regexp.MustCompile(`gdpr\s*(masked|redacted|protected)|(per|due\s*to|under)\s*gdpr`),
+	regexp.MustCompile(`data\s*protected`),
+	regexp.MustCompile(`statutory\s*masking`),
+	regexp.MustCompile(`query\s*the\s*rdds`),
+	regexp.MustCompile(`request\s*email\s*form`),
+	regexp.MustCompile(`hidden\s*(upon|by)`),
+	regexp.MustCompile(`^private$`),
+}
+
+// PrivacyClassifier classifies contact values.
+// Add patterns to the lists before using it to recognize more services.
+type PrivacyClassifier struct {
+	Services []PrivacyService
+	Redacted []*regexp.Regexp
+}
+
+// NewPrivacyClassifier creates PrivacyClassifier with default patterns.
+func NewPrivacyClassifier() *PrivacyClassifier {
+	return &PrivacyClassifier{
+		Services: append([]PrivacyService(nil), DefaultPrivacyServices...),
+		Redacted: append([]*regexp.Regexp(nil), DefaultRedactedPatterns...),
+	}
+}
+
+// DefaultPrivacyClassifier is used by Contact methods, the timeline and ownership detection.
+var DefaultPrivacyClassifier = NewPrivacyClassifier()
+
+// ClassifyValue returns the class of the value and the name of the privacy service if any.
+// Redacted patterns are tried before services, so "REDACTED FOR PRIVACY" is redacted.
+// All services are tried regardless of their fields.
+func (pc *PrivacyClassifier) ClassifyValue(value string) (FieldClass, string) {
+	return pc.ClassifyField("", value)
+}
+
+// ClassifyField is like ClassifyValue but tries only services applying
+// to the contact field with the JSON name like "organization".
+func (pc *PrivacyClassifier) ClassifyField(field, value string) (FieldClass, string) {
+	value = strings.ToLower(strings.TrimSpace(value))
+	if value == "" {
+		return FieldEmpty, ""
+	}
+	for _, re := range pc.Redacted {
+		if re.MatchString(value) {
+			return FieldRedacted, ""
+		}
+	}
+	for _, s := range pc.Services {
+		if field != "" && s.Fields != nil && !hasString(s.Fields, field) {
+			continue
+		}
+		if s.Pattern.MatchString(value) {
+			return FieldPrivacy, s.Name
+		}
+	}
+	return FieldData, ""
+}
+
+func hasString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// ContactPrivacy is the classification of contact fields.
+type ContactPrivacy struct {
+	// Fields maps JSON names of contact fields to their classes.
+	// RawText is not classified.
+	Fields map[string]FieldClass
+	// Services are names of privacy services found in the contact.
+	Services []string
+}
+
+// contactValue is a classified field of the contact with its JSON name.
+type contactValue struct {
+	field string
+	value string
+}
+
+func contactValues(c *Contact) []contactValue {
+	return []contactValue{
+		{"name", c.Name},
+		{"organization", c.Organization},
+		{"street", c.Street},
+		{"city", c.City},
+		{"state", c.State},
+		{"postalCode", c.PostalCode},
+		{"country", c.Country},
+		{"email", c.Email},
+		{"telephone", c.Telephone},
+		{"telephoneExt", c.TelephoneExt},
+		{"fax", c.Fax},
+		{"faxExt", c.FaxExt},
+	}
+}
+
+// Classify classifies every field of the contact.
+func (pc *PrivacyClassifier) Classify(c *Contact) ContactPrivacy {
+	p := ContactPrivacy{Fields: make(map[string]FieldClass)}
+	seen := make(map[string]bool)
+	for _, v := range contactValues(c) {
+		class, service := pc.ClassifyField(v.field, v.value)
+		p.Fields[v.field] = class
+		if service != "" && !seen[service] {
+			seen[service] = true
+			p.Services = append(p.Services, service)
+		}
+	}
+	return p
+}
+
+// RealFields returns JSON names of fields carrying real data in the order of Contact fields.
+func (p ContactPrivacy) RealFields() []string {
+	var fields []string
+	for _, v := range contactValues(&Contact{}) {
+		if p.Fields[v.field] == FieldData {
+			fields = append(fields, v.field)
+		}
+	}
+	return fields
+}
+
+// Masked reports whether any field is redacted or belongs to a privacy service.
+func (p ContactPrivacy) Masked() bool {
+	for _, class := range p.Fields {
+		if class == FieldRedacted || class == FieldPrivacy {
+			return true
+		}
+	}
+	return false
+}
+
+// Privacy classifies fields of the contact with DefaultPrivacyClassifier.
+func (c *Contact) Privacy() ContactPrivacy {
+	return DefaultPrivacyClassifier.Classify(c)
+}
+
+// RealFields returns JSON names of fields carrying real data.
+func (c *Contact) RealFields() []string {
+	return c.Privacy().RealFields()
+}
+
+// IsRedacted reports whether the identity of the contact is hidden:
+// none of name, organization and email carries real data while some of them is masked.
+func (c *Contact) IsRedacted() bool {
+	p := c.Privacy()
+	masked := false
+	for _, f := range identityFields {
+		switch p.Fields[f] {
+		case FieldData:
+			return false
+		case FieldRedacted, FieldPrivacy:
+			masked = true
+		}
+	}
+	return masked
+}
+
+// realValue returns the value of the contact field if it carries real data or empty string otherwise.
+func realValue(field, value string) string {
+	if class, _ := DefaultPrivacyClassifier.ClassifyField(field, value); class != FieldData {
+		return ""
+	}
+	return value
+}
+
+// maskingChanged reports whether any of name, organization and email
+// switched between real data and a masked value.
+func maskingChanged(old, cur *Contact) bool {
+	oldPrivacy, curPrivacy := old.Privacy(), cur.Privacy()
+	for _, f := range identityFields {
+		o, c := oldPrivacy.Fields[f], curPrivacy.Fields[f]
+		if o == FieldData && (c == FieldRedacted || c == FieldPrivacy) ||
+			c == FieldData && (o == FieldRedacted || o == FieldPrivacy) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,135 @@
+package whoishistory
+
+import (
+	"reflect"
+	"regexp"
+	"testing"
+)
+
+func TestClassifyValue(t *testing.T) {
+	tests := []struct {
+		value   string
+		class   FieldClass
+		service string
+	}{
+		{" ", FieldEmpty, ""},
+		{"REDACTED FOR PRIVACY", FieldRedacted, ""},
+		{"Data Protected", FieldRedacted, ""},
+		{"Not Disclosed", FieldRedacted, ""},
+		{"Private", FieldRedacted, ""},
+		{"f3a9@DomainsByProxy.com", FieldPrivacy, "Domains By Proxy"},
+		{"WhoisGuard Protected", FieldPrivacy, "WhoisGuard"},
+		{"Withheld for Privacy ehf", FieldPrivacy, "Withheld for Privacy"},
+		{"Proxy Registration Inc", FieldPrivacy, "Privacy service"},
+		{"Proxyma LLC", FieldData, ""},
+		{"Privacy International", FieldData, ""},
+		{"Open Proxy Project", FieldData, ""},
+		{"GDPR Advisors Ltd", FieldData, ""},
+		{"Registrant data masked due to GDPR", FieldRedacted, ""},
+		{"John Smith", FieldData, ""},
+	}
+
+	for _, tt := range tests {
+		class, service := DefaultPrivacyClassifier.ClassifyValue(tt.value)
+		if class != tt.class || service != tt.service {
+			t.Errorf("ClassifyValue(%q) = %q, %q, want %q, %q", tt.value, class, service, tt.class, tt.service)
+		}
+	}
+}
+
+func TestContactPrivacy(t *testing.T) {
+	c := Contact{
+		Name:         "REDACTED FOR PRIVACY",
+		Organization: "Domains By Proxy, LLC",
+		City:         "Tempe",
+		Country:      "UNITED STATES",
+		Email:        "f3a9@domainsbyproxy.com",
+		Telephone:    "+1.4806242599",
+	}
+
+	p := c.Privacy()
+	if p.Fields["name"] != FieldRedacted || p.Fields["organization"] != FieldPrivacy || p.Fields["street"] != FieldEmpty {
+		t.Errorf("Fields = %v", p.Fields)
+	}
+	if !reflect.DeepEqual(p.Services, []string{"Domains By Proxy"}) {
+		t.Errorf("Services = %v", p.Services)
+	}
+	if want := []string{"city", "country", "telephone"}; !reflect.DeepEqual(c.RealFields(), want) {
+		t.Errorf("RealFields() = %v, want %v", c.RealFields(), want)
+	}
+	if !p.Masked() || !c.IsRedacted() {
+		t.Error("contact is not redacted")
+	}
+
+	c.Email = "john@example.com"
+	if c.IsRedacted() {
+		t.Error("contact with real email is redacted")
+	}
+	if (&Contact{}).IsRedacted() {
+		t.Error("empty contact is redacted")
+	}
+}
+
+func TestClassifyField(t *testing.T) {
+	if class, _ := DefaultPrivacyClassifier.ClassifyField("organization", "Private Registration Proxy Service"); class != FieldPrivacy {
+		t.Errorf("organization class = %q", class)
+	}
+	if class, _ := DefaultPrivacyClassifier.ClassifyField("street", "1 Privacy Service Road"); class != FieldData {
+		t.Errorf("street class = %q", class)
+	}
+}
+
+func TestDetectOwnershipChangesRealPrivacyOrganization(t *testing.T) {
+	records := []*WhoisRecord{
+		{DomainName: "a.test", Audit: Audit{UpdatedDate: mustTime(t, "2015-01-01")},
+			RegistrantContact: Contact{Organization: "Privacy International", Email: "info@privacyinternational.org"}},
+		{DomainName: "a.test", Audit: Audit{UpdatedDate: mustTime(t, "2016-01-01")},
+			RegistrantContact: Contact{Organization: "Acme", Email: "info@acme.test"}},
+	}
+
+	changes := DetectOwnershipChanges(records)
+	if len(changes) != 1 || changes[0].Confidence != ConfidenceHigh {
+		t.Errorf("changes = %+v", changes)
+	}
+	if events := NewTimeline(records).EventsOf(EventOwnershipChange); len(events) != 1 {
+		t.Errorf("ownership events = %+v", events)
+	}
+}
+
+func TestPrivacyClassifierCustomService(t *testing.T) {
+	pc := NewPrivacyClassifier()
+	pc.Services = append([]PrivacyService{{Name: "Hidden Owner", Pattern: regexp.MustCompile(`hidden\s*owner`)}}, pc.Services...)
+
+	if _, service := pc.ClassifyValue("HiddenOwner Ltd"); service != "Hidden Owner" {
+		t.Errorf("service = %q", service)
+	}
+	if class, _ := DefaultPrivacyClassifier.ClassifyValue("HiddenOwner Ltd"); class != FieldData {
+		t.Errorf("default classifier is changed: %q", class)
+	}
+}
+
+func TestTimelinePrivacyChange(t *testing.T) {
+	record := func(audit string, registrant Contact) *WhoisRecord {
+		return &WhoisRecord{
+			DomainName:        "a.test",
+			Audit:             Audit{UpdatedDate: mustTime(t, audit)},
+			RegistrantContact: registrant,
+		}
+	}
+
+	records := []*WhoisRecord{
+		record("2017-01-01", Contact{Name: "John Smith", Email: "john@example.com"}),
+		record("2018-06-01", Contact{Name: "REDACTED FOR PRIVACY", Email: "Select Request Email Form"}),
+		record("2019-01-01", Contact{Name: "Redacted for privacy", Email: "abc@withheldforprivacy.com"}),
+	}
+
+	tl := NewTimeline(records)
+	if events := tl.EventsOf(EventOwnershipChange); len(events) != 0 {
+		t.Errorf("ownership events = %+v", events)
+	}
+	events := tl.EventsOf(EventPrivacyChange)
+	if len(events) != 1 || events[0].Time.String() != "2018-06-01T00:00:00+00:00" ||
+		!reflect.DeepEqual(events[0].Records, records[:2]) {
+		t.Errorf("privacy events = %+v", events)
+	}
+}
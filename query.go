@@ -0,0 +1,204 @@
+package whoishistory
+
+import (
+	"encoding/json"
+	"net/url"
+	"time"
+)
+
+// DateRange is a range of dates. Zero bounds are not set.
+type DateRange struct {
+	From time.Time
+	To   time.Time
+}
+
+// IsZero reports whether neither bound is set.
+func (r DateRange) IsZero() bool {
+	return r.From.IsZero() && r.To.IsZero()
+}
+
+// Query is the typed form of parameters of HistoricService calls.
+// Unlike Option it can be inspected, validated, compared and serialized.
+// Zero fields are not set. Dates are sent without time in format 2006-01-02.
+type Query struct {
+	Domain string
+	// Mode is either "preview" or "purchase".
+	Mode         string
+	OutputFormat string
+	SinceDate    time.Time
+	Created      DateRange
+	Updated      DateRange
+	Expired      DateRange
+}
+
+// queryDate is a date parameter of Query
+type queryDate struct {
+	key  string
+	date *time.Time
+}
+
+func (q *Query) queryDates() []queryDate {
+	return []queryDate{
+		{"sinceDate", &q.SinceDate},
+		{"createdDateFrom", &q.Created.From},
+		{"createdDateTo", &q.Created.To},
+		{"updatedDateFrom", &q.Updated.From},
+		{"updatedDateTo", &q.Updated.To},
+		{"expiredDateFrom", &q.Expired.From},
+		{"expiredDateTo", &q.Expired.To},
+	}
+}
+
+// ParseQuery creates Query from URL query parameters.
+// Unknown parameters are ignored.
+func ParseQuery(v url.Values) (Query, error) {
+	q := Query{
+		Domain:       v.Get("domainName"),
+		Mode:         v.Get("mode"),
+		OutputFormat: v.Get("outputFormat"),
+	}
+
+	for _, d := range q.queryDates() {
+		s := v.Get(d.key)
+		if s == "" {
+			continue
+		}
+		date, err := time.Parse(dateFormat, s)
+		if err != nil {
+			return Query{}, &ArgError{d.key, "is not a date in format " + dateFormat}
+		}
+		*d.date = date
+	}
+
+	return q, nil
+}
+
+// QueryFromOptions creates Query from options.
+func QueryFromOptions(opts ...Option) (Query, error) {
+	v := url.Values{}
+	for _, opt := range opts {
+		opt(v)
+	}
+	return ParseQuery(v)
+}
+
+// Values returns URL query parameters of the query.
+func (q Query) Values() url.Values {
+	v := url.Values{}
+	if q.Domain != "" {
+		v.Set("domainName", q.Domain)
+	}
+	if q.Mode != "" {
+		v.Set("mode", q.Mode)
+	}
+	for _, o := range q.Options() {
+		o(v)
+	}
+	return v
+}
+
+// Options returns options setting the query filters and the output format.
+// Domain and Mode are not included as they are set by the called method.
+func (q Query) Options() []Option {
+	var opts []Option
+	if q.OutputFormat != "" {
+		format := q.OutputFormat
+		opts = append(opts, func(v url.Values) {
+			v.Set("outputFormat", format)
+		})
+	}
+
+	for _, d := range q.queryDates() {
+		if d.date.IsZero() {
+			continue
+		}
+		key, date := d.key, *d.date
+		opts = append(opts, func(v url.Values) {
+			v.Set(key, date.Format(dateFormat))
+		})
+	}
+
+	return opts
+}
+
+// Validate checks that mode is known, date ranges are not reversed
+// and the since date is not in the future. It returns ArgErrors listing every problem.
+func (q Query) Validate() error {
+	return q.validate(time.Now()).err()
+}
+
+func (q Query) validate(now time.Time) ArgErrors {
+	var errs ArgErrors
+
+	if q.Mode != "" && q.Mode != modePreview && q.Mode != modePurchase {
+		errs = append(errs, &ArgError{"mode", "must be either preview or purchase"})
+	}
+
+	if dateAfter(q.SinceDate, now) {
+		errs = append(errs, &ArgError{"sinceDate", "is in the future"})
+	}
+
+	for _, r := range []struct {
+		name  string
+		dates DateRange
+	}{
+		{"createdDate", q.Created},
+		{"updatedDate", q.Updated},
+		{"expiredDate", q.Expired},
+	} {
+		if dateAfter(r.dates.From, r.dates.To) {
+			errs = append(errs, &ArgError{r.name + "From", "is after " + r.name + "To"})
+		}
+	}
+
+	return errs
+}
+
+// dateAfter reports whether the date of a is after the date of b.
+// Time of the day is ignored as it's not sent.
+func dateAfter(a, b time.Time) bool {
+	if a.IsZero() || b.IsZero() {
+		return false
+	}
+	return a.Format(dateFormat) > b.In(a.Location()).Format(dateFormat)
+}
+
+// Equal reports whether queries produce the same parameters.
+func (q Query) Equal(other Query) bool {
+	return q.String() == other.String()
+}
+
+// String returns the query in URL encoding.
+func (q Query) String() string {
+	return q.Values().Encode()
+}
+
+// MarshalJSON encodes the query as an object of its URL query parameters.
+func (q Query) MarshalJSON() ([]byte, error) {
+	v := q.Values()
+	m := make(map[string]string, len(v))
+	for k := range v {
+		m[k] = v.Get(k)
+	}
+	return json.Marshal(m)
+}
+
+// UnmarshalJSON decodes the object of URL query parameters.
+func (q *Query) UnmarshalJSON(b []byte) error {
+	var m map[string]string
+	if err := json.Unmarshal(b, &m); err != nil {
+		return err
+	}
+
+	v := make(url.Values, len(m))
+	for k, s := range m {
+		v.Set(k, s)
+	}
+
+	parsed, err := ParseQuery(v)
+	if err != nil {
+		return err
+	}
+	*q = parsed
+	return nil
+}
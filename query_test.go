@@ -0,0 +1,105 @@
+package whoishistory
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestQuery(t *testing.T) {
+	d := func(day int) time.Time {
+		return time.Date(2020, 1, day, 0, 0, 0, 0, time.UTC)
+	}
+
+	q := Query{
+		Domain:    "test.test",
+		Mode:      modePurchase,
+		SinceDate: d(1),
+		Created:   DateRange{From: d(2), To: d(3)},
+		Expired:   DateRange{To: d(4)},
+	}
+
+	const want = "createdDateFrom=2020-01-02&createdDateTo=2020-01-03&domainName=test.test" +
+		"&expiredDateTo=2020-01-04&mode=purchase&sinceDate=2020-01-01"
+	if got := q.String(); got != want {
+		t.Errorf("String() = %v, want %v", got, want)
+	}
+
+	parsed, err := ParseQuery(q.Values())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !parsed.Equal(q) || parsed.SinceDate != d(1) || !parsed.Updated.IsZero() {
+		t.Errorf("ParseQuery() = %+v", parsed)
+	}
+
+	fromOptions, err := QueryFromOptions(append(q.Options(), OptionUpdatedDateFrom(d(5)))...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fromOptions.Domain != "" || fromOptions.Created != q.Created || fromOptions.Updated.From != d(5) {
+		t.Errorf("QueryFromOptions() = %+v", fromOptions)
+	}
+
+	b, err := json.Marshal(q)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded Query
+	if err := json.Unmarshal(b, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if !decoded.Equal(q) {
+		t.Errorf("JSON round-trip %s = %+v", b, decoded)
+	}
+
+	_, err = ParseQuery(url.Values{"sinceDate": {"yesterday"}})
+	checkErr(t, err, `invalid argument: "sinceDate" is not a date in format 2006-01-02`)
+	err = json.Unmarshal([]byte(`{"createdDateTo":"2020-13-01"}`), &decoded)
+	checkErr(t, err, `invalid argument: "createdDateTo" is not a date in format 2006-01-02`)
+}
+
+func TestQueryValidate(t *testing.T) {
+	morning := time.Date(2020, 1, 2, 8, 0, 0, 0, time.UTC)
+	evening := time.Date(2020, 1, 2, 20, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		query   Query
+		wantErr string
+	}{
+		{query: Query{}},
+		{query: Query{Created: DateRange{From: evening, To: morning}}},
+		{query: Query{Updated: DateRange{From: evening}}},
+		{
+			query:   Query{Expired: DateRange{From: evening.AddDate(0, 0, 1), To: morning}},
+			wantErr: `invalid argument: "expiredDateFrom" is after expiredDateTo`,
+		},
+		{
+			query:   Query{Mode: "buy"},
+			wantErr: `invalid argument: "mode" must be either preview or purchase`,
+		},
+	}
+	for _, tt := range tests {
+		checkErr(t, tt.query.Validate(), tt.wantErr)
+	}
+}
+
+func TestAPI_ResponseQuery(t *testing.T) {
+	server := whoisServer(`{"recordsCount":1,"records":[]}`, "")
+	defer server.Close()
+
+	api := newAPI(server, pathWhoisResponseOK)
+
+	since := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	_, resp, err := api.Preview(context.Background(), "test.test", OptionSinceDate(since), OptionCacheBypass())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := Query{Domain: "test.test", Mode: modePreview, OutputFormat: "JSON", SinceDate: since}
+	if resp.Query == nil || !resp.Query.Equal(want) {
+		t.Errorf("Response.Query = %+v, want %+v", resp.Query, want)
+	}
+}
@@ -0,0 +1,123 @@
+package whoishistory
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Limiter blocks until a request is allowed to proceed or the context is done.
+// *RateLimiter implements it as well as *rate.Limiter from golang.org/x/time/rate.
+type Limiter interface {
+	Wait(ctx context.Context) error
+}
+
+// RateLimits holds limiters applied by Client.Do per request mode.
+// A nil limiter means no limit. The same RateLimits value can be passed to
+// several clients using the same API key to share the limits between them.
+type RateLimits struct {
+	// Preview limits requests in preview mode.
+	Preview Limiter
+	// Purchase limits requests in purchase mode.
+	Purchase Limiter
+}
+
+// limiter returns the limiter for the request or nil.
+func (r *RateLimits) limiter(req *http.Request) Limiter {
+	if r == nil {
+		return nil
+	}
+	switch requestMode(req) {
+	case modePreview:
+		return r.Preview
+	case modePurchase:
+		return r.Purchase
+	}
+	return nil
+}
+
+// RateLimiter is a token bucket rate limiter safe for concurrent use.
+type RateLimiter struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+
+	now func() time.Time
+}
+
+var _ Limiter = &RateLimiter{}
+
+// NewRateLimiter creates RateLimiter which allows perSecond requests per second
+// on average and bursts of up to burst requests. Non-positive perSecond means no limit.
+func NewRateLimiter(perSecond float64, burst int) *RateLimiter {
+	if burst < 1 {
+		burst = 1
+	}
+	return &RateLimiter{
+		rate:   perSecond,
+		burst:  float64(burst),
+		tokens: float64(burst),
+		now:    time.Now,
+	}
+}
+
+// Wait takes a token from the bucket waiting for it if necessary.
+// The token is returned to the bucket if the context is done first.
+func (l *RateLimiter) Wait(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	d := l.reserve()
+	if d <= 0 {
+		return nil
+	}
+
+	if err := sleep(ctx, d); err != nil {
+		l.cancel()
+		return err
+	}
+	return nil
+}
+
+// reserve takes a token and returns how long to wait until it's available.
+func (l *RateLimiter) reserve() time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.rate <= 0 {
+		return 0
+	}
+
+	now := time.Now()
+	if l.now != nil {
+		now = l.now()
+	}
+	if !l.last.IsZero() {
+		l.tokens += now.Sub(l.last).Seconds() * l.rate
+		if l.tokens > l.burst {
+			l.tokens = l.burst
+		}
+	}
+	l.last = now
+
+	l.tokens--
+	if l.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-l.tokens / l.rate * float64(time.Second))
+}
+
+// cancel returns the reserved token.
+func (l *RateLimiter) cancel() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.tokens++
+	if l.tokens > l.burst {
+		l.tokens = l.burst
+	}
+}
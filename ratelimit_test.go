@@ -0,0 +1,114 @@
+package whoishistory
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRateLimiterReserve(t *testing.T) {
+	now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	l := NewRateLimiter(2, 2)
+	l.now = func() time.Time { return now }
+
+	want := []time.Duration{0, 0, 500 * time.Millisecond, time.Second}
+	for i, w := range want {
+		if got := l.reserve(); got != w {
+			t.Errorf("reserve() #%d = %v, want %v", i, got, w)
+		}
+	}
+
+	// Two seconds later four tokens are refilled: two pay off the debt.
+	now = now.Add(2 * time.Second)
+	if got := l.reserve(); got != 0 {
+		t.Errorf("reserve() after refill = %v, want 0", got)
+	}
+
+	l.cancel()
+	if l.tokens != 2 {
+		t.Errorf("tokens after cancel = %v, want 2", l.tokens)
+	}
+}
+
+func TestRateLimiterUnlimited(t *testing.T) {
+	l := NewRateLimiter(0, 1)
+	for i := 0; i < 100; i++ {
+		if err := l.Wait(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestRateLimiterWaitCanceled(t *testing.T) {
+	l := NewRateLimiter(0.001, 1)
+
+	if err := l.Wait(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	checkErr(t, l.Wait(ctx), "context deadline exceeded")
+
+	if l.tokens < -0.01 {
+		t.Errorf("token was not returned: %v", l.tokens)
+	}
+}
+
+func TestRateLimitsByMode(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		_, _ = w.Write([]byte(`{"recordsCount":1,"records":[{"domainName":"test.test"}]}`))
+	}))
+	defer server.Close()
+
+	apiURL, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	preview := &countingLimiter{}
+	purchase := &countingLimiter{}
+	limits := &RateLimits{Preview: preview, Purchase: purchase}
+
+	// Both clients share the same limits.
+	for i := 0; i < 2; i++ {
+		api := NewClient(apiKey, ClientParams{
+			HTTPClient:      server.Client(),
+			HistoricBaseURL: apiURL,
+			RateLimits:      limits,
+		})
+		if _, _, err := api.Preview(context.Background(), "whoisxmlapi.com"); err != nil {
+			t.Fatal(err)
+		}
+		if _, _, err := api.Purchase(context.Background(), "whoisxmlapi.com"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if preview.count() != 2 || purchase.count() != 2 {
+		t.Errorf("waits = %v/%v, want 2/2", preview.count(), purchase.count())
+	}
+}
+
+type countingLimiter struct {
+	mu sync.Mutex
+	n  int
+}
+
+func (l *countingLimiter) Wait(ctx context.Context) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.n++
+	return nil
+}
+
+func (l *countingLimiter) count() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.n
+}
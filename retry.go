@@ -0,0 +1,208 @@
+package whoishistory
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math"
+	"math/rand"
+	"net"
+	"net/http"
+	"strconv"
+	"syscall"
+	"time"
+)
+
+// RetryPolicy describes how Client.Do retries failed requests.
+// A nil policy means that every request is sent exactly once.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts including the first one.
+	// Values less than 2 disable retries.
+	MaxAttempts int
+	// BaseDelay is the delay before the first retry. Every next retry
+	// doubles the delay.
+	BaseDelay time.Duration
+	// MaxDelay caps the computed backoff delay and the Retry-After delay.
+	// Zero means defaultMaxDelay.
+	MaxDelay time.Duration
+	// Jitter is a fraction of the delay in range [0, 1] which is randomly
+	// subtracted from it to spread retries of concurrent callers.
+	Jitter float64
+	// RetryableStatusCodes lists HTTP status codes worth retrying.
+	// If it's nil then DefaultRetryableStatusCodes are used.
+	RetryableStatusCodes []int
+	// RetryableError reports whether a transport error is worth retrying.
+	// If it's nil then IsRetryableError is used.
+	RetryableError func(err error) bool
+	// RespectRetryAfter makes the client wait at least as long as
+	// the Retry-After response header says but no longer than MaxDelay.
+	RespectRetryAfter bool
+	// RetryPurchase enables retries of purchase requests.
+	// A retried purchase may cost credits twice, so it's disabled by default.
+	RetryPurchase bool
+}
+
+// defaultMaxDelay caps retry delays of policies without MaxDelay.
+const defaultMaxDelay = 30 * time.Second
+
+// DefaultRetryableStatusCodes are HTTP status codes retried by default.
+var DefaultRetryableStatusCodes = []int{
+	http.StatusRequestTimeout,
+	http.StatusTooManyRequests,
+	http.StatusInternalServerError,
+	http.StatusBadGateway,
+	http.StatusServiceUnavailable,
+	http.StatusGatewayTimeout,
+}
+
+// DefaultRetryPolicy returns the recommended retry policy.
+// Purchase requests are not retried.
+func DefaultRetryPolicy() *RetryPolicy {
+	return &RetryPolicy{
+		MaxAttempts:       4,
+		BaseDelay:         500 * time.Millisecond,
+		MaxDelay:          30 * time.Second,
+		Jitter:            0.5,
+		RespectRetryAfter: true,
+	}
+}
+
+// IsRetryableError reports whether the transport error is transient:
+// timeouts, connection resets and unexpectedly closed connections.
+// Context cancellation is never retryable.
+func IsRetryableError(err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return true
+	}
+	if errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, syscall.ECONNABORTED) || errors.Is(err, syscall.EPIPE) {
+		return true
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+	return false
+}
+
+// allows reports whether the request may be sent more than once.
+func (p *RetryPolicy) allows(req *http.Request) bool {
+	if p == nil || p.MaxAttempts < 2 {
+		return false
+	}
+	if requestMode(req) == modePurchase && !p.RetryPurchase {
+		return false
+	}
+	return true
+}
+
+func (p *RetryPolicy) retryableStatus(code int) bool {
+	codes := p.RetryableStatusCodes
+	if codes == nil {
+		codes = DefaultRetryableStatusCodes
+	}
+	for _, c := range codes {
+		if c == code {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *RetryPolicy) retryableError(err error) bool {
+	if p.RetryableError != nil {
+		return p.RetryableError(err)
+	}
+	return IsRetryableError(err)
+}
+
+// delay computes the pause before the attempt following the given one.
+// Both the backoff and Retry-After are capped by MaxDelay.
+func (p *RetryPolicy) delay(attempt int, resp *http.Response) time.Duration {
+	maxDelay := p.MaxDelay
+	if maxDelay <= 0 {
+		maxDelay = defaultMaxDelay
+	}
+
+	// the float product may be +Inf or exceed the range of time.Duration
+	d := float64(p.BaseDelay) * math.Pow(2, float64(attempt-1))
+	if d > float64(maxDelay) || math.IsNaN(d) {
+		d = float64(maxDelay)
+	}
+	if d < 0 {
+		d = 0
+	}
+	if p.Jitter > 0 {
+		j := p.Jitter
+		if j > 1 {
+			j = 1
+		}
+		d -= d * j * rand.Float64()
+	}
+
+	wait := time.Duration(d)
+	if p.RespectRetryAfter && resp != nil {
+		if ra, ok := parseRetryAfter(resp.Header.Get("Retry-After"), time.Now()); ok && ra > wait {
+			wait = ra
+		}
+	}
+	if wait > maxDelay {
+		wait = maxDelay
+	}
+	return wait
+}
+
+// parseRetryAfter parses the Retry-After header value which is either
+// a number of seconds or an HTTP date.
+func parseRetryAfter(value string, now time.Time) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if secs, err := strconv.Atoi(value); err == nil {
+		if secs < 0 {
+			return 0, false
+		}
+		return time.Duration(secs) * time.Second, true
+	}
+	t, err := http.ParseTime(value)
+	if err != nil {
+		return 0, false
+	}
+	d := t.Sub(now)
+	if d < 0 {
+		d = 0
+	}
+	return d, true
+}
+
+// sleep pauses for d or until the context is done.
+func sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+const (
+	modePreview  = "preview"
+	modePurchase = "purchase"
+)
+
+// requestMode returns the value of the `mode` query parameter.
+func requestMode(req *http.Request) string {
+	return req.URL.Query().Get("mode")
+}
@@ -0,0 +1,214 @@
+package whoishistory
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func flakyServer(failures int32, status int) (*httptest.Server, *int32) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if atomic.AddInt32(&calls, 1) <= failures {
+			w.WriteHeader(status)
+			_, _ = w.Write([]byte(`{"code":` + strconv.Itoa(status) + `,"messages":"` + http.StatusText(status) + `"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"recordsCount":1,"records":[{"domainName":"test.test"}]}`))
+	}))
+	return server, &calls
+}
+
+func newRetryAPI(server *httptest.Server, policy *RetryPolicy) *Client {
+	apiURL, err := url.Parse(server.URL)
+	if err != nil {
+		panic(err)
+	}
+	return NewClient(apiKey, ClientParams{
+		HTTPClient:      server.Client(),
+		HistoricBaseURL: apiURL,
+		Retry:           policy,
+	})
+}
+
+func TestRetry(t *testing.T) {
+	policy := &RetryPolicy{
+		MaxAttempts: 3,
+		BaseDelay:   time.Millisecond,
+		MaxDelay:    5 * time.Millisecond,
+		Jitter:      0.5,
+	}
+	purchasePolicy := *policy
+	purchasePolicy.RetryPurchase = true
+
+	tests := []struct {
+		name         string
+		failures     int32
+		status       int
+		policy       *RetryPolicy
+		purchase     bool
+		wantAttempts int
+		wantCalls    int32
+		wantErr      bool
+	}{
+		{
+			name:         "no policy",
+			failures:     1,
+			status:       http.StatusServiceUnavailable,
+			policy:       nil,
+			wantAttempts: 0,
+			wantCalls:    1,
+			wantErr:      true,
+		},
+		{
+			name:         "recovered preview",
+			failures:     2,
+			status:       http.StatusServiceUnavailable,
+			policy:       policy,
+			wantAttempts: 3,
+			wantCalls:    3,
+		},
+		{
+			name:         "attempts exhausted",
+			failures:     5,
+			status:       http.StatusTooManyRequests,
+			policy:       policy,
+			wantAttempts: 0,
+			wantCalls:    3,
+			wantErr:      true,
+		},
+		{
+			name:         "non retryable status",
+			failures:     1,
+			status:       http.StatusUnauthorized,
+			policy:       policy,
+			wantAttempts: 0,
+			wantCalls:    1,
+			wantErr:      true,
+		},
+		{
+			name:         "purchase is not retried by default",
+			failures:     1,
+			status:       http.StatusServiceUnavailable,
+			policy:       policy,
+			purchase:     true,
+			wantAttempts: 0,
+			wantCalls:    1,
+			wantErr:      true,
+		},
+		{
+			name:         "purchase retries opted in",
+			failures:     1,
+			status:       http.StatusServiceUnavailable,
+			policy:       &purchasePolicy,
+			purchase:     true,
+			wantAttempts: 2,
+			wantCalls:    2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, calls := flakyServer(tt.failures, tt.status)
+			defer server.Close()
+
+			api := newRetryAPI(server, tt.policy)
+
+			var resp *Response
+			var err error
+			if tt.purchase {
+				_, resp, err = api.Purchase(context.Background(), "whoisxmlapi.com")
+			} else {
+				_, resp, err = api.Preview(context.Background(), "whoisxmlapi.com")
+			}
+
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got := atomic.LoadInt32(calls); got != tt.wantCalls {
+				t.Errorf("calls = %v, want %v", got, tt.wantCalls)
+			}
+			if !tt.wantErr && resp.Attempts != tt.wantAttempts {
+				t.Errorf("attempts = %v, want %v", resp.Attempts, tt.wantAttempts)
+			}
+		})
+	}
+}
+
+func TestRetryContextCanceled(t *testing.T) {
+	server, _ := flakyServer(10, http.StatusServiceUnavailable)
+	defer server.Close()
+
+	api := newRetryAPI(server, &RetryPolicy{MaxAttempts: 5, BaseDelay: time.Hour})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	_, _, err := api.Preview(ctx, "whoisxmlapi.com")
+	checkErr(t, err, "cannot execute request: context deadline exceeded")
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		value  string
+		want   time.Duration
+		wantOK bool
+	}{
+		{value: "", want: 0, wantOK: false},
+		{value: "3", want: 3 * time.Second, wantOK: true},
+		{value: "-1", want: 0, wantOK: false},
+		{value: "Wed, 01 Jan 2020 00:00:10 GMT", want: 10 * time.Second, wantOK: true},
+		{value: "Tue, 31 Dec 2019 23:59:00 GMT", want: 0, wantOK: true},
+		{value: "soon", want: 0, wantOK: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, ok := parseRetryAfter(tt.value, now)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("parseRetryAfter() = %v, %v, want %v, %v", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestRetryDelay(t *testing.T) {
+	p := &RetryPolicy{BaseDelay: 100 * time.Millisecond, MaxDelay: 300 * time.Millisecond}
+
+	want := []time.Duration{100 * time.Millisecond, 200 * time.Millisecond, 300 * time.Millisecond, 300 * time.Millisecond}
+	for i, w := range want {
+		if got := p.delay(i+1, nil); got != w {
+			t.Errorf("delay(%d) = %v, want %v", i+1, got, w)
+		}
+	}
+
+	p.RespectRetryAfter = true
+	p.MaxDelay = 5 * time.Second
+	resp := &http.Response{Header: http.Header{"Retry-After": []string{"2"}}}
+	if got := p.delay(1, resp); got != 2*time.Second {
+		t.Errorf("delay() with Retry-After = %v, want %v", got, 2*time.Second)
+	}
+
+	resp.Header.Set("Retry-After", "86400")
+	if got := p.delay(1, resp); got != 5*time.Second {
+		t.Errorf("delay() with long Retry-After = %v, want %v", got, 5*time.Second)
+	}
+}
+
+func TestRetryDelayDefaultMax(t *testing.T) {
+	p := &RetryPolicy{BaseDelay: time.Second, Jitter: 0.5}
+
+	for _, attempt := range []int{1, 10, 100, 5000} {
+		if got := p.delay(attempt, nil); got < 0 || got > defaultMaxDelay {
+			t.Errorf("delay(%d) = %v, want between 0 and %v", attempt, got, defaultMaxDelay)
+		}
+	}
+	if got := p.delay(5000, nil); got < defaultMaxDelay/2 {
+		t.Errorf("delay(5000) = %v, want at least %v", got, defaultMaxDelay/2)
+	}
+}
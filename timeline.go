@@ -0,0 +1,207 @@
+package whoishistory
+
+import (
+	"sort"
+	"strings"
+)
+
+// EventType is the type of a timeline event.
+type EventType string
+
+// Types of timeline events.
+const (
+	EventRegistration      EventType = "registration"
+	EventRenewal           EventType = "renewal"
+	EventExpiry            EventType = "expiry"
+	EventRegistrarTransfer EventType = "registrar_transfer"
+	EventOwnershipChange   EventType = "ownership_change"
+	// EventPrivacyChange means that registrant data became redacted or
+	// hidden by a privacy service, or was disclosed again.
+	EventPrivacyChange    EventType = "privacy_change"
+	EventNameServerChange EventType = "nameserver_change"
+	EventStatusChange     EventType = "status_change"
+)
+
+// Event is a change in the history of a domain.
+type Event struct {
+	Type EventType
+	// Time is the date of the event if it's known from the record like
+	// the creation or expiration date, or the time of the snapshot which shows it otherwise.
+	Time Time
+	// Records are the records the event is derived from:
+	// the last one before the event if any and the first one showing it.
+	Records []*WhoisRecord
+	// Changes are the changes of the records which make the event.
+	Changes []Change
+}
+
+// Snapshot is a record observed unchanged in consecutive records.
+type Snapshot struct {
+	// Record is the first of identical records.
+	Record *WhoisRecord
+	// Records are the identical records in order.
+	Records []*WhoisRecord
+	// First and Last are the times of the first and the last identical records.
+	First Time
+	Last  Time
+}
+
+// Timeline is the ordered history of one domain.
+type Timeline struct {
+	Domain    string
+	Snapshots []*Snapshot
+	Events    []Event
+}
+
+// SnapshotTime returns the time the record was observed: Audit.UpdatedDate
+// or the update date of the domain if the former is not set.
+func SnapshotTime(r *WhoisRecord) Time {
+	if !r.Audit.UpdatedDate.IsZero() {
+		return r.Audit.UpdatedDate
+	}
+	t, _ := r.UpdatedDate()
+	return t
+}
+
+// NewTimeline builds the timeline from records of one domain in any order.
+// Records are sorted by SnapshotTime, records without time come first.
+// Consecutive records which Diff finds equal are collapsed into one snapshot.
+func NewTimeline(records []*WhoisRecord) *Timeline {
+	sorted := make([]*WhoisRecord, 0, len(records))
+	for _, r := range records {
+		if r != nil {
+			sorted = append(sorted, r)
+		}
+	}
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return SnapshotTime(sorted[i]).Before(SnapshotTime(sorted[j]))
+	})
+
+	tl := &Timeline{}
+	var last *Snapshot
+	for _, r := range sorted {
+		if tl.Domain == "" {
+			tl.Domain = r.DomainName
+		}
+		if last != nil && Diff(last.Record, r).Empty() {
+			last.Records = append(last.Records, r)
+			last.Last = SnapshotTime(r)
+			continue
+		}
+		last = &Snapshot{Record: r, Records: []*WhoisRecord{r}, First: SnapshotTime(r), Last: SnapshotTime(r)}
+		tl.Snapshots = append(tl.Snapshots, last)
+	}
+
+	var prev *Snapshot
+	expired := make(map[string]bool)
+	for _, s := range tl.Snapshots {
+		for _, e := range snapshotEvents(prev, s) {
+			// the expiration date stays the same in the following snapshots
+			if e.Type == EventExpiry {
+				if expired[e.Time.String()] {
+					continue
+				}
+				expired[e.Time.String()] = true
+			}
+			tl.Events = append(tl.Events, e)
+		}
+		prev = s
+	}
+	sort.SliceStable(tl.Events, func(i, j int) bool {
+		return tl.Events[i].Time.Before(tl.Events[j].Time)
+	})
+
+	return tl
+}
+
+// snapshotEvents returns events shown by the snapshot compared to the previous one.
+func snapshotEvents(prev, cur *Snapshot) []Event {
+	rec := cur.Record
+	created, _ := rec.CreatedDate()
+
+	if prev == nil {
+		if created.IsZero() {
+			return nil
+		}
+		return []Event{{Type: EventRegistration, Time: created, Records: []*WhoisRecord{rec}}}
+	}
+
+	old := prev.Record
+	records := []*WhoisRecord{old, rec}
+	diff := Diff(old, rec)
+
+	var events []Event
+	add := func(t EventType, at Time, fields ...string) {
+		events = append(events, Event{Type: t, Time: at, Records: records, Changes: changesOf(diff, fields...)})
+	}
+
+	oldCreated, _ := old.CreatedDate()
+	oldExpires, _ := old.ExpiresDate()
+	expires, _ := rec.ExpiresDate()
+	reregistered := !created.IsZero() && !oldCreated.IsZero() && created.After(oldCreated)
+
+	switch {
+	case reregistered:
+		if !oldExpires.IsZero() && !oldExpires.After(created) {
+			add(EventExpiry, oldExpires, "expiresDateISO8601", "expiresDateRaw")
+		}
+		add(EventRegistration, created, "createdDateISO8601", "createdDateRaw")
+	case !oldExpires.IsZero() && expires.After(oldExpires):
+		add(EventRenewal, cur.First, "expiresDateISO8601", "expiresDateRaw")
+	case !oldExpires.IsZero() && oldExpires.Before(cur.First):
+		add(EventExpiry, oldExpires)
+	}
+
+	if textChanged(old.RegistrarName, rec.RegistrarName) {
+		add(EventRegistrarTransfer, cur.First, "registrarName")
+	}
+	if registrantChanged(&old.RegistrantContact, &rec.RegistrantContact) {
+		add(EventOwnershipChange, cur.First, "registrantContact.name", "registrantContact.organization", "registrantContact.email")
+	}
+	if maskingChanged(&old.RegistrantContact, &rec.RegistrantContact) {
+		add(EventPrivacyChange, cur.First, "registrantContact.name", "registrantContact.organization", "registrantContact.email")
+	}
+	if len(changesOf(diff, fieldNameServers)) > 0 {
+		add(EventNameServerChange, cur.First, fieldNameServers)
+	}
+	if len(changesOf(diff, fieldStatus)) > 0 {
+		add(EventStatusChange, cur.First, fieldStatus)
+	}
+
+	return events
+}
+
+// changesOf returns changes of the fields.
+func changesOf(diff *RecordDiff, fields ...string) []Change {
+	var changes []Change
+	for _, c := range diff.Changes {
+		for _, f := range fields {
+			if c.Field == f {
+				changes = append(changes, c)
+			}
+		}
+	}
+	return changes
+}
+
+// textChanged compares values ignoring case and whitespace.
+// Values are not considered changed if either of them is empty.
+func textChanged(a, b string) bool {
+	a, b = normalizeText(a), normalizeText(b)
+	return a != "" && b != "" && a != b
+}
+
+func normalizeText(s string) string {
+	return strings.ToLower(strings.Join(strings.Fields(s), " "))
+}
+
+// EventsOf returns events of the type in order.
+func (tl *Timeline) EventsOf(t EventType) []Event {
+	var events []Event
+	for _, e := range tl.Events {
+		if e.Type == t {
+			events = append(events, e)
+		}
+	}
+	return events
+}
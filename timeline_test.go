@@ -0,0 +1,91 @@
+package whoishistory
+
+import (
+	"reflect"
+	"testing"
+)
+
+func mustTime(t *testing.T, s string) Time {
+	t.Helper()
+	v, err := ParseTime(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return v
+}
+
+func TestTimeline(t *testing.T) {
+	record := func(audit, created, expires, registrar, ns, registrant string, status ...string) *WhoisRecord {
+		return &WhoisRecord{
+			DomainName:         "a.test",
+			Audit:              Audit{UpdatedDate: mustTime(t, audit)},
+			CreatedDateRaw:     created,
+			ExpiresDateISO8601: mustTime(t, expires),
+			RegistrarName:      registrar,
+			NameServers:        []string{ns},
+			Status:             status,
+			RegistrantContact:  Contact{Name: registrant},
+		}
+	}
+
+	r1 := record("2010-01-01", "01-may-2009", "2010-05-01", "Registrar A", "ns1.test", "John")
+	r1dup := record("2010-02-01", "01-may-2009", "2010-05-01", "Registrar A", "ns1.test", "John")
+	r2 := record("2010-06-01", "01-may-2009", "2011-05-01", "Registrar B", "ns2.test", "john ")
+	r3 := record("2011-07-01", "01-may-2009", "2011-05-01", "Registrar B", "ns2.test", "john ", "clientHold")
+	r4 := record("2011-09-01", "15-aug-2011", "2012-08-15", "Registrar B", "ns2.test", "Jane")
+
+	tl := NewTimeline([]*WhoisRecord{r3, r1dup, nil, r4, r1, r2})
+
+	if tl.Domain != "a.test" || len(tl.Snapshots) != 4 {
+		t.Fatalf("timeline = %+v", tl)
+	}
+	if s := tl.Snapshots[0]; !reflect.DeepEqual(s.Records, []*WhoisRecord{r1, r1dup}) ||
+		s.First.String() != "2010-01-01T00:00:00+00:00" || s.Last.String() != "2010-02-01T00:00:00+00:00" {
+		t.Errorf("collapsed snapshot = %+v", s)
+	}
+
+	type event struct {
+		Type    EventType
+		Time    string
+		Records []*WhoisRecord
+	}
+	want := []event{
+		{EventRegistration, "2009-05-01T00:00:00+00:00", []*WhoisRecord{r1}},
+		{EventRenewal, "2010-06-01T00:00:00+00:00", []*WhoisRecord{r1, r2}},
+		{EventRegistrarTransfer, "2010-06-01T00:00:00+00:00", []*WhoisRecord{r1, r2}},
+		{EventNameServerChange, "2010-06-01T00:00:00+00:00", []*WhoisRecord{r1, r2}},
+		{EventExpiry, "2011-05-01T00:00:00+00:00", []*WhoisRecord{r2, r3}},
+		{EventStatusChange, "2011-07-01T00:00:00+00:00", []*WhoisRecord{r2, r3}},
+		{EventRegistration, "2011-08-15T00:00:00+00:00", []*WhoisRecord{r3, r4}},
+		{EventOwnershipChange, "2011-09-01T00:00:00+00:00", []*WhoisRecord{r3, r4}},
+		{EventStatusChange, "2011-09-01T00:00:00+00:00", []*WhoisRecord{r3, r4}},
+	}
+	var got []event
+	for _, e := range tl.Events {
+		got = append(got, event{e.Type, e.Time.String(), e.Records})
+	}
+	if !reflect.DeepEqual(got, want) {
+		for _, e := range got {
+			t.Logf("%s %s", e.Type, e.Time)
+		}
+		t.Fatal("unexpected events")
+	}
+
+	transfer := tl.EventsOf(EventRegistrarTransfer)
+	wantChanges := []Change{{Field: "registrarName", Kind: ChangeModified, Old: "Registrar A", New: "Registrar B"}}
+	if len(transfer) != 1 || !reflect.DeepEqual(transfer[0].Changes, wantChanges) {
+		t.Errorf("transfer = %+v", transfer)
+	}
+}
+
+func TestTimelineEmpty(t *testing.T) {
+	tl := NewTimeline(nil)
+	if tl.Domain != "" || len(tl.Snapshots) != 0 || len(tl.Events) != 0 {
+		t.Errorf("timeline = %+v", tl)
+	}
+
+	tl = NewTimeline([]*WhoisRecord{{DomainName: "a.test"}})
+	if len(tl.Snapshots) != 1 || len(tl.Events) != 0 {
+		t.Errorf("timeline = %+v", tl)
+	}
+}
@@ -0,0 +1,196 @@
+package whoishistory
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Tracer creates spans around HistoricService calls.
+// It can be implemented on top of any distributed tracing library.
+type Tracer interface {
+	// Start starts a span which is a child of the span in the context.
+	Start(ctx context.Context, name string) (context.Context, Span)
+	// Inject writes the span of the context into headers of the outgoing request.
+	Inject(ctx context.Context, header http.Header)
+}
+
+// Span is a traced operation.
+type Span interface {
+	SetAttribute(key string, value interface{})
+	RecordError(err error)
+	End()
+}
+
+// Span names used by the client.
+const (
+	SpanPreview  = "whoishistory.Preview"
+	SpanPurchase = "whoishistory.Purchase"
+	SpanBuild    = "whoishistory.build"
+	SpanHTTP     = "whoishistory.http"
+	SpanDecode   = "whoishistory.decode"
+)
+
+type noopSpan struct{}
+
+func (noopSpan) SetAttribute(key string, value interface{}) {}
+func (noopSpan) RecordError(err error)                      {}
+func (noopSpan) End()                                       {}
+
+// startSpan starts a span with the tracer or returns a no-op span if it's nil.
+func startSpan(ctx context.Context, tracer Tracer, name string) (context.Context, Span) {
+	if tracer == nil {
+		return ctx, noopSpan{}
+	}
+	return tracer.Start(ctx, name)
+}
+
+// ownKeys are query parameters set by the service itself
+var ownKeys = map[string]bool{"domainName": true, "mode": true, "outputFormat": true}
+
+// traceOutcome tags the span of HistoricService call with its outcome.
+func traceOutcome(span Span, outcome *Outcome) {
+	var keys []string
+	for k := range outcome.Query {
+		if !ownKeys[k] {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	span.SetAttribute("options", keys)
+
+	if resp := outcome.Response; resp != nil && resp.Response != nil {
+		span.SetAttribute("http.status_code", resp.StatusCode)
+		span.SetAttribute("cache_hit", resp.CacheHit)
+	}
+	if outcome.Err != nil {
+		span.RecordError(outcome.Err)
+		return
+	}
+	span.SetAttribute("records", outcome.RecordsCount)
+}
+
+// RecordedSpan is a span recorded by RecordingTracer.
+type RecordedSpan struct {
+	mu sync.Mutex
+
+	Name       string
+	TraceID    string
+	SpanID     string
+	ParentID   string
+	Attributes map[string]interface{}
+	Errors     []error
+	StartTime  time.Time
+	EndTime    time.Time
+}
+
+var _ Span = &RecordedSpan{}
+
+// SetAttribute sets the attribute value.
+func (s *RecordedSpan) SetAttribute(key string, value interface{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.Attributes[key] = value
+}
+
+// RecordError appends the error to the span.
+func (s *RecordedSpan) RecordError(err error) {
+	if err == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.Errors = append(s.Errors, err)
+}
+
+// End sets the end time of the span.
+func (s *RecordedSpan) End() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.EndTime = time.Now()
+}
+
+// Ended reports whether End was called.
+func (s *RecordedSpan) Ended() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return !s.EndTime.IsZero()
+}
+
+// Attribute returns the attribute value.
+func (s *RecordedSpan) Attribute(key string) interface{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.Attributes[key]
+}
+
+// RecordingTracer is an in-memory Tracer for tests.
+// It propagates spans with the W3C traceparent header.
+type RecordingTracer struct {
+	mu    sync.Mutex
+	spans []*RecordedSpan
+}
+
+var _ Tracer = &RecordingTracer{}
+
+type recordedSpanKey struct{}
+
+// Start records a new span.
+func (t *RecordingTracer) Start(ctx context.Context, name string) (context.Context, Span) {
+	span := &RecordedSpan{
+		Name:       name,
+		SpanID:     randomHex(8),
+		Attributes: make(map[string]interface{}),
+		StartTime:  time.Now(),
+	}
+	if parent, ok := ctx.Value(recordedSpanKey{}).(*RecordedSpan); ok {
+		span.TraceID = parent.TraceID
+		span.ParentID = parent.SpanID
+	} else {
+		span.TraceID = randomHex(16)
+	}
+
+	t.mu.Lock()
+	t.spans = append(t.spans, span)
+	t.mu.Unlock()
+
+	return context.WithValue(ctx, recordedSpanKey{}, span), span
+}
+
+// Inject sets the traceparent header of the span in the context.
+func (t *RecordingTracer) Inject(ctx context.Context, header http.Header) {
+	if span, ok := ctx.Value(recordedSpanKey{}).(*RecordedSpan); ok {
+		header.Set("traceparent", "00-"+span.TraceID+"-"+span.SpanID+"-01")
+	}
+}
+
+// Spans returns recorded spans in the order they were started.
+func (t *RecordingTracer) Spans() []*RecordedSpan {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return append([]*RecordedSpan(nil), t.spans...)
+}
+
+// Reset removes recorded spans.
+func (t *RecordingTracer) Reset() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.spans = nil
+}
+
+func randomHex(n int) string {
+	b := make([]byte, n)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
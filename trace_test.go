@@ -0,0 +1,116 @@
+package whoishistory
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestTracer(t *testing.T) {
+	var traceparent string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		traceparent = req.Header.Get("traceparent")
+		_, _ = w.Write([]byte(`{"recordsCount":2,"records":[{"domainName":"a.test"},{"domainName":"b.test"}]}`))
+	}))
+	defer server.Close()
+
+	apiURL, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tracer := &RecordingTracer{}
+	api := NewClient(apiKey, ClientParams{
+		HTTPClient:      server.Client(),
+		HistoricBaseURL: apiURL,
+		Tracer:          tracer,
+	})
+
+	ctx, parent := tracer.Start(context.Background(), "caller")
+
+	_, _, err = api.Purchase(ctx, "test.test",
+		OptionCreatedDateFrom(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)),
+		OptionSinceDate(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	parent.End()
+
+	spans := tracer.Spans()
+
+	var names []string
+	for _, s := range spans {
+		names = append(names, s.Name)
+		if !s.Ended() {
+			t.Errorf("span %s is not ended", s.Name)
+		}
+		if s.TraceID != spans[0].TraceID {
+			t.Errorf("span %s has another trace", s.Name)
+		}
+	}
+	want := []string{"caller", SpanPurchase, SpanBuild, SpanHTTP, SpanDecode}
+	if !reflect.DeepEqual(names, want) {
+		t.Fatalf("spans = %v, want %v", names, want)
+	}
+
+	root, httpSpan := spans[1], spans[3]
+	if root.ParentID != spans[0].SpanID {
+		t.Errorf("root parent = %v, want %v", root.ParentID, spans[0].SpanID)
+	}
+	for _, s := range spans[2:] {
+		if s.ParentID != root.SpanID {
+			t.Errorf("span %s parent = %v, want %v", s.Name, s.ParentID, root.SpanID)
+		}
+	}
+
+	if want := "00-" + httpSpan.TraceID + "-" + httpSpan.SpanID + "-01"; traceparent != want {
+		t.Errorf("traceparent = %v, want %v", traceparent, want)
+	}
+
+	attrs := map[string]interface{}{
+		"domain":           "test.test",
+		"mode":             modePurchase,
+		"options":          []string{"createdDateFrom", "sinceDate"},
+		"http.status_code": 200,
+		"cache_hit":        false,
+		"records":          2,
+	}
+	for k, v := range attrs {
+		if got := root.Attribute(k); !reflect.DeepEqual(got, v) {
+			t.Errorf("attribute %s = %v, want %v", k, got, v)
+		}
+	}
+	if got := httpSpan.Attribute("attempts"); got != 1 {
+		t.Errorf("http attempts = %v", got)
+	}
+}
+
+func TestTracerError(t *testing.T) {
+	tracer := &RecordingTracer{}
+	api := NewClient(apiKey, ClientParams{Tracer: tracer})
+
+	_, _, err := api.Preview(context.Background(), "")
+	if err == nil {
+		t.Fatal("error expected")
+	}
+
+	spans := tracer.Spans()
+	if len(spans) != 2 || spans[0].Name != SpanPreview || spans[1].Name != SpanBuild {
+		t.Fatalf("spans = %+v", spans)
+	}
+	for _, s := range spans {
+		if len(s.Errors) != 1 || s.Errors[0] != err {
+			t.Errorf("span %s errors = %v", s.Name, s.Errors)
+		}
+	}
+
+	tracer.Reset()
+	if len(tracer.Spans()) != 0 {
+		t.Error("Reset() did not remove spans")
+	}
+}
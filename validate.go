@@ -0,0 +1,82 @@
+package whoishistory
+
+import (
+	"net/url"
+	"strings"
+	"time"
+)
+
+// ArgErrors lists problems found by validation.
+// errors.As finds the first ArgError in the list.
+type ArgErrors []*ArgError
+
+func (e ArgErrors) Error() string {
+	msgs := make([]string, len(e))
+	for i, err := range e {
+		msgs[i] = err.Error()
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// Unwrap returns the first error.
+func (e ArgErrors) Unwrap() error {
+	if len(e) == 0 {
+		return nil
+	}
+	return e[0]
+}
+
+// err returns nil if the list is empty.
+func (e ArgErrors) err() error {
+	if len(e) == 0 {
+		return nil
+	}
+	return e
+}
+
+// validateParam is a query parameter used by OptionSkipValidation.
+// It's removed from the query before sending the request.
+const validateParam = "whoishistory.validate"
+
+// OptionSkipValidation sends the query as is without validating it.
+func OptionSkipValidation() Option {
+	return func(v url.Values) {
+		v.Set(validateParam, "skip")
+	}
+}
+
+// validateValues checks the final query parameters before sending them.
+// Dates must be well-formed and not zero in addition to Query.Validate checks.
+func validateValues(v url.Values, now time.Time) error {
+	var errs ArgErrors
+
+	zero := time.Time{}.Format(dateFormat)
+	valid := url.Values{}
+	for k, vv := range v {
+		valid[k] = vv
+	}
+
+	for _, d := range (&Query{}).queryDates() {
+		s := v.Get(d.key)
+		if s == "" {
+			continue
+		}
+		if s == zero {
+			errs = append(errs, &ArgError{d.key, "is zero time"})
+			valid.Del(d.key)
+			continue
+		}
+		if _, err := time.Parse(dateFormat, s); err != nil {
+			errs = append(errs, &ArgError{d.key, "is not a date in format " + dateFormat})
+			valid.Del(d.key)
+		}
+	}
+
+	q, err := ParseQuery(valid)
+	if err != nil {
+		return err
+	}
+	errs = append(errs, q.validate(now)...)
+
+	return errs.err()
+}
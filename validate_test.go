@@ -0,0 +1,86 @@
+package whoishistory
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestValidateValues(t *testing.T) {
+	now := time.Date(2020, 6, 1, 12, 0, 0, 0, time.UTC)
+	d := func(month, day int) time.Time {
+		return time.Date(2020, time.Month(month), day, 0, 0, 0, 0, time.UTC)
+	}
+
+	tests := []struct {
+		name    string
+		opts    []Option
+		wantErr string
+	}{
+		{
+			name: "valid",
+			opts: []Option{OptionSinceDate(now), OptionCreatedDateFrom(d(1, 1)), OptionCreatedDateTo(d(1, 1))},
+		},
+		{
+			name:    "reversed range",
+			opts:    []Option{OptionUpdatedDateFrom(d(2, 1)), OptionUpdatedDateTo(d(1, 1))},
+			wantErr: `invalid argument: "updatedDateFrom" is after updatedDateTo`,
+		},
+		{
+			name:    "future since date",
+			opts:    []Option{OptionSinceDate(now.AddDate(0, 0, 1))},
+			wantErr: `invalid argument: "sinceDate" is in the future`,
+		},
+		{
+			name: "every problem",
+			opts: []Option{
+				OptionExpiredDateTo(time.Time{}),
+				OptionCreatedDateFrom(d(3, 1)),
+				OptionCreatedDateTo(d(2, 1)),
+				func(v url.Values) { v.Set("updatedDateFrom", "June") },
+			},
+			wantErr: `invalid argument: "updatedDateFrom" is not a date in format 2006-01-02; ` +
+				`invalid argument: "expiredDateTo" is zero time; ` +
+				`invalid argument: "createdDateFrom" is after createdDateTo`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := url.Values{}
+			for _, opt := range tt.opts {
+				opt(v)
+			}
+			err := validateValues(v, now)
+			checkErr(t, err, tt.wantErr)
+
+			var argErr *ArgError
+			if err != nil && (!errors.As(err, &argErr) || ErrorKind(err) != "argument") {
+				t.Errorf("error %v is not ArgError", err)
+			}
+		})
+	}
+}
+
+func TestAPI_Validation(t *testing.T) {
+	server := whoisServer(`{"recordsCount":1,"records":[]}`, "")
+	defer server.Close()
+
+	api := newAPI(server, pathWhoisResponseOK)
+	ctx := context.Background()
+
+	_, resp, err := api.Preview(ctx, "test.test", OptionSinceDate(time.Time{}))
+	checkErr(t, err, `invalid argument: "sinceDate" is zero time`)
+	if resp != nil {
+		t.Error("request is sent")
+	}
+
+	_, resp, err = api.Preview(ctx, "test.test", OptionSinceDate(time.Time{}), OptionSkipValidation())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := resp.Request.URL.Query(); got.Get("sinceDate") != "0001-01-01" || got.Get(validateParam) != "" {
+		t.Errorf("query = %v", got)
+	}
+}
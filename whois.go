@@ -0,0 +1,241 @@
+package whoishistory
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+const defaultWhoisURL = `https://www.whoisxmlapi.com/whoisserver/WhoisService`
+
+// WhoisService is an interface for WHOIS API
+type WhoisService interface {
+	Get(ctx context.Context, name string) (*WhoisRecord, *Response, error)
+}
+
+type whoisServiceOp struct {
+	client  *Client
+	baseURL *url.URL
+}
+
+var _ WhoisService = &whoisServiceOp{}
+
+func (service *whoisServiceOp) newRequest() (*http.Request, error) {
+
+	u, _ := url.Parse(service.baseURL.String())
+
+	req, err := service.client.NewRequest(http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	query := url.Values{}
+	query.Set("outputFormat", "JSON")
+	query.Set("apiKey", service.client.apiKey)
+
+	req.URL.RawQuery = query.Encode()
+
+	return req, nil
+}
+
+// whoisContact is a contact as WHOIS API returns it
+type whoisContact struct {
+	Name         string `json:"name"`
+	Organization string `json:"organization"`
+	Street1      string `json:"street1"`
+	Street2      string `json:"street2"`
+	Street3      string `json:"street3"`
+	Street4      string `json:"street4"`
+	City         string `json:"city"`
+	State        string `json:"state"`
+	PostalCode   string `json:"postalCode"`
+	Country      string `json:"country"`
+	Email        string `json:"email"`
+	Telephone    string `json:"telephone"`
+	TelephoneExt string `json:"telephoneExt"`
+	Fax          string `json:"fax"`
+	FaxExt       string `json:"faxExt"`
+	RawText      string `json:"rawText"`
+}
+
+func (c *whoisContact) contact() Contact {
+	if c == nil {
+		return Contact{}
+	}
+
+	var street []string
+	for _, s := range []string{c.Street1, c.Street2, c.Street3, c.Street4} {
+		if s != "" {
+			street = append(street, s)
+		}
+	}
+
+	return Contact{
+		Name:         c.Name,
+		Organization: c.Organization,
+		Street:       strings.Join(street, ", "),
+		City:         c.City,
+		State:        c.State,
+		PostalCode:   c.PostalCode,
+		Country:      c.Country,
+		Email:        c.Email,
+		Telephone:    c.Telephone,
+		TelephoneExt: c.TelephoneExt,
+		Fax:          c.Fax,
+		FaxExt:       c.FaxExt,
+		RawText:      c.RawText,
+	}
+}
+
+// whoisData is a set of fields shared by WhoisRecord and its registryData
+type whoisData struct {
+	DomainName  string `json:"domainName"`
+	CreatedDate string `json:"createdDate"`
+	UpdatedDate string `json:"updatedDate"`
+	ExpiresDate string `json:"expiresDate"`
+	NameServers *struct {
+		HostNames []string `json:"hostNames"`
+	} `json:"nameServers"`
+	WhoisServer           string        `json:"whoisServer"`
+	RegistrarName         string        `json:"registrarName"`
+	Status                string        `json:"status"`
+	StrippedText          string        `json:"strippedText"`
+	RawText               string        `json:"rawText"`
+	Registrant            *whoisContact `json:"registrant"`
+	AdministrativeContact *whoisContact `json:"administrativeContact"`
+	TechnicalContact      *whoisContact `json:"technicalContact"`
+	BillingContact        *whoisContact `json:"billingContact"`
+	ZoneContact           *whoisContact `json:"zoneContact"`
+	Audit                 struct {
+		CreatedDate string `json:"createdDate"`
+		UpdatedDate string `json:"updatedDate"`
+	} `json:"audit"`
+}
+
+type whoisRecord struct {
+	whoisData
+	RegistryData *whoisData `json:"registryData"`
+}
+
+type whoisResponse struct {
+	WhoisRecord  *whoisRecord `json:"WhoisRecord"`
+	ErrorMessage *struct {
+		ErrorCode string `json:"errorCode"`
+		Msg       string `json:"msg"`
+	} `json:"ErrorMessage"`
+}
+
+// parseWhoisTime parses the date of WHOIS API ignoring invalid values.
+func parseWhoisTime(s string) Time {
+	t, _ := ParseTime(s)
+	return t
+}
+
+// record converts WHOIS API record to WhoisRecord.
+// Empty fields are filled from the registry data.
+func (r *whoisRecord) record() *WhoisRecord {
+	d := r.whoisData
+	if reg := r.RegistryData; reg != nil {
+		pick := func(a, b string) string {
+			if a != "" {
+				return a
+			}
+			return b
+		}
+		d.DomainName = pick(d.DomainName, reg.DomainName)
+		d.CreatedDate = pick(d.CreatedDate, reg.CreatedDate)
+		d.UpdatedDate = pick(d.UpdatedDate, reg.UpdatedDate)
+		d.ExpiresDate = pick(d.ExpiresDate, reg.ExpiresDate)
+		d.WhoisServer = pick(d.WhoisServer, reg.WhoisServer)
+		d.RegistrarName = pick(d.RegistrarName, reg.RegistrarName)
+		d.Status = pick(d.Status, reg.Status)
+		if d.NameServers == nil || len(d.NameServers.HostNames) == 0 {
+			d.NameServers = reg.NameServers
+		}
+	}
+
+	rec := &WhoisRecord{
+		DomainName:         d.DomainName,
+		CreatedDateISO8601: parseWhoisTime(d.CreatedDate),
+		UpdatedDateISO8601: parseWhoisTime(d.UpdatedDate),
+		ExpiresDateISO8601: parseWhoisTime(d.ExpiresDate),
+		CreatedDateRaw:     d.CreatedDate,
+		UpdatedDateRaw:     d.UpdatedDate,
+		ExpiresDateRaw:     d.ExpiresDate,
+		Audit: Audit{
+			CreatedDate: parseWhoisTime(d.Audit.CreatedDate),
+			UpdatedDate: parseWhoisTime(d.Audit.UpdatedDate),
+		},
+		WhoisServer:           d.WhoisServer,
+		RegistrarName:         d.RegistrarName,
+		Status:                strings.Fields(d.Status),
+		CleanText:             d.StrippedText,
+		RawText:               d.RawText,
+		RegistrantContact:     d.Registrant.contact(),
+		AdministrativeContact: d.AdministrativeContact.contact(),
+		TechnicalContact:      d.TechnicalContact.contact(),
+		BillingContact:        d.BillingContact.contact(),
+		ZoneContact:           d.ZoneContact.contact(),
+	}
+	if d.NameServers != nil {
+		rec.NameServers = d.NameServers.HostNames
+	}
+
+	return rec
+}
+
+// Get returns the current WHOIS record of the domain.
+// The name is normalized with NormalizeDomain like in HistoricService calls.
+func (service *whoisServiceOp) Get(ctx context.Context, name string) (*WhoisRecord, *Response, error) {
+	name, err := NormalizeDomain(name)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	req, err := service.newRequest()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	q := req.URL.Query()
+	q.Set("domainName", name)
+	req.URL.RawQuery = q.Encode()
+
+	var b strings.Builder
+	resp, err := service.client.Do(ctx, req, &b)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	response := whoisResponse{}
+
+	err = json.NewDecoder(strings.NewReader(b.String())).Decode(&response)
+
+	respErr := checkResponse(resp.Response, b.String())
+	if err == nil && response.ErrorMessage != nil {
+		if respErr == nil {
+			respErr = newErrorResponse(resp.Response, b.String())
+		}
+		msg := response.ErrorMessage
+		respErr.Message = strings.TrimPrefix(msg.ErrorCode+": "+msg.Msg, ": ")
+	}
+	if respErr != nil {
+		return nil, resp, respErr
+	}
+
+	if err != nil {
+		return nil, resp, fmt.Errorf("cannot parse response: %w", err)
+	}
+
+	if response.WhoisRecord == nil {
+		return nil, resp, fmt.Errorf("cannot parse response: %s", "WhoisRecord is missing")
+	}
+
+	record := response.WhoisRecord.record()
+	service.client.textLimits.apply(record)
+
+	return record, resp, nil
+}
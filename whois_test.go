@@ -0,0 +1,123 @@
+package whoishistory
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestAPI_WhoisGet(t *testing.T) {
+
+	ctx := context.Background()
+
+	const resp = `{"WhoisRecord":{
+		"domainName":"test.test",
+		"createdDate":"2019-02-12T10:20:30Z",
+		"expiresDate":"",
+		"status":"clientTransferProhibited serverDeleteProhibited",
+		"strippedText":"stripped",
+		"registrant":{"name":"reg-name","street1":"line 1","street2":"line 2","email":"reg@test.test"},
+		"audit":{"createdDate":"2020-05-31 19:55:41 UTC","updatedDate":"2020-05-31 19:55:41 UTC"},
+		"registryData":{
+			"domainName":"test.test",
+			"updatedDate":"2020-01-02T03:04:05+0000",
+			"registrarName":"Registrar",
+			"nameServers":{"hostNames":["ns1.test.test","ns2.test.test"]}
+		}
+	}}`
+
+	const errResp = `{"ErrorMessage":{"errorCode":"WHOIS_01","msg":"test error"}}`
+
+	server := whoisServer(resp, errResp)
+	defer server.Close()
+
+	tests := []struct {
+		name    string
+		path    string
+		domain  string
+		want    bool
+		wantErr string
+	}{
+		{
+			name:   "successfull request",
+			path:   pathWhoisResponseOK,
+			domain: "test.test",
+			want:   true,
+		},
+		{
+			name:    "empty name",
+			path:    pathWhoisResponseOK,
+			domain:  "",
+			wantErr: `invalid argument: "name" cannot be empty`,
+		},
+		{
+			name:    "non 200 status code",
+			path:    pathWhoisResponse500,
+			domain:  "test.test",
+			wantErr: "API failed with status code: 500 (WHOIS_01: test error)",
+		},
+		{
+			name:    "partial response 1",
+			path:    pathWhoisResponsePartial1,
+			domain:  "test.test",
+			wantErr: "cannot parse response: unexpected EOF",
+		},
+		{
+			name:    "partial response 2",
+			path:    pathWhoisResponsePartial2,
+			domain:  "test.test",
+			wantErr: "cannot read response: unexpected EOF",
+		},
+		{
+			name:    "could not process request",
+			path:    pathWhoisResponseError,
+			domain:  "test.test",
+			wantErr: "API failed with status code: 200 (WHOIS_01: test error)",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			api := newAPI(server, tt.path)
+
+			got, _, err := api.WhoisService.Get(ctx, tt.domain)
+			checkErr(t, err, tt.wantErr)
+
+			if !tt.want {
+				if got != nil {
+					t.Errorf("Whois.Get() got = %v, expected nil", got)
+				}
+				return
+			}
+
+			want := &WhoisRecord{
+				DomainName:         "test.test",
+				CreatedDateISO8601: Time(time.Date(2019, 2, 12, 10, 20, 30, 0, time.UTC)),
+				UpdatedDateISO8601: Time(time.Date(2020, 1, 2, 3, 4, 5, 0, time.FixedZone("", 0))),
+				CreatedDateRaw:     "2019-02-12T10:20:30Z",
+				UpdatedDateRaw:     "2020-01-02T03:04:05+0000",
+				Audit: Audit{
+					CreatedDate: Time(time.Date(2020, 5, 31, 19, 55, 41, 0, time.UTC)),
+					UpdatedDate: Time(time.Date(2020, 5, 31, 19, 55, 41, 0, time.UTC)),
+				},
+				NameServers:   []string{"ns1.test.test", "ns2.test.test"},
+				RegistrarName: "Registrar",
+				Status:        []string{"clientTransferProhibited", "serverDeleteProhibited"},
+				CleanText:     "stripped",
+				RegistrantContact: Contact{
+					Name:   "reg-name",
+					Street: "line 1, line 2",
+					Email:  "reg@test.test",
+				},
+			}
+
+			gotJSON, _ := json.Marshal(got)
+			wantJSON, _ := json.Marshal(want)
+			if string(gotJSON) != string(wantJSON) {
+				t.Errorf("got  = %s", gotJSON)
+				t.Errorf("want = %s", wantJSON)
+			}
+		})
+	}
+}